@@ -17,6 +17,7 @@ var ExecCmd = &cobra.Command{
 func init() {
 	ExecCmd.PersistentFlags().StringVarP(&urlICQ, UrlFlagName, "u", "http://localhost:9999", "server url")
 	ExecCmd.AddCommand(resubmitFailedTx)
+	ExecCmd.AddCommand(resubmitFailedKV)
 	rootCmd.AddCommand(ExecCmd)
 }
 
@@ -56,3 +57,36 @@ var resubmitFailedTx = &cobra.Command{
 		return nil
 	},
 }
+
+// resubmitFailedKV represents the resubmit-kv command
+var resubmitFailedKV = &cobra.Command{
+	Use:   "resubmit-kv <queryID>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Resubmit KV query result with fresh values and proofs after unsuccessful submission",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := cmd.Flags().GetString(UrlFlagName)
+		if err != nil {
+			return err
+		}
+
+		client, err := icqhttp.NewICQClient(url)
+		if err != nil {
+			return fmt.Errorf("failed to get new icq client: %w", err)
+		}
+
+		queryID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse queryID: %w", err)
+		}
+
+		req := icqhttp.ResubmitKVRequest{QueryIDs: []uint64{uint64(queryID)}}
+
+		err = client.ResubmitKVs(req)
+		if err != nil {
+			return fmt.Errorf("failed to resubmit unsuccessful kv: %w", err)
+		}
+
+		fmt.Printf("KV queryID=%d resubmitted successfully", queryID)
+		return nil
+	},
+}
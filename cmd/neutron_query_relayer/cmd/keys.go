@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client/input"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/go-bip39"
+	neutronapp "github.com/neutron-org/neutron/app"
+	"github.com/spf13/cobra"
+	rpcclienthttp "github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	"github.com/neutron-org/neutron-query-relayer/internal/raw"
+	"github.com/neutron-org/neutron-query-relayer/internal/submit"
+)
+
+const (
+	ChainIDFlagName = "chain-id"
+	YesFlagName     = "yes"
+)
+
+// KeysCmd represents the keys command
+var KeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the relayer keys in the keyring configured by RELAYER_NEUTRON_CHAIN_* env variables",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// set global values for prefixes for cosmos-sdk to print neutron addresses
+		neutronapp.GetDefaultConfig()
+	},
+}
+
+func init() {
+	KeysCmd.PersistentFlags().String(ChainIDFlagName, "", "neutron chain id used as the keyring name, queried from RELAYER_NEUTRON_CHAIN_RPC_ADDR if empty")
+	keysDelete.Flags().Bool(YesFlagName, false, "delete the key without confirmation")
+	KeysCmd.AddCommand(keysAdd)
+	KeysCmd.AddCommand(keysImportMnemonic)
+	KeysCmd.AddCommand(keysList)
+	KeysCmd.AddCommand(keysShow)
+	KeysCmd.AddCommand(keysDelete)
+	KeysCmd.AddCommand(keysExport)
+	rootCmd.AddCommand(KeysCmd)
+}
+
+// keysAdd represents the keys add command
+var keysAdd = &cobra.Command{
+	Use:   "add <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Create a new key and print its mnemonic",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, _, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		info, mnemonic, err := keybase.NewMnemonic(args[0], keyring.English, sdk.GetConfig().GetFullBIP44Path(),
+			keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		if err != nil {
+			return fmt.Errorf("failed to create key %s: %w", args[0], err)
+		}
+
+		fmt.Printf("Key %s created, address: %s\n", info.GetName(), info.GetAddress().String())
+		fmt.Printf("Write this mnemonic phrase in a safe place, it is the only way to recover the key:\n\n%s\n", mnemonic)
+		return nil
+	},
+}
+
+// keysImportMnemonic represents the keys import-mnemonic command
+var keysImportMnemonic = &cobra.Command{
+	Use:   "import-mnemonic <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a key from the bip39 mnemonic read from stdin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, _, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		mnemonic, err := input.GetString("Enter your bip39 mnemonic", bufio.NewReader(os.Stdin))
+		if err != nil {
+			return fmt.Errorf("failed to read mnemonic: %w", err)
+		}
+		if !bip39.IsMnemonicValid(mnemonic) {
+			return fmt.Errorf("invalid mnemonic")
+		}
+
+		info, err := keybase.NewAccount(args[0], mnemonic, keyring.DefaultBIP39Passphrase, sdk.GetConfig().GetFullBIP44Path(), hd.Secp256k1)
+		if err != nil {
+			return fmt.Errorf("failed to import key %s: %w", args[0], err)
+		}
+
+		fmt.Printf("Key %s imported, address: %s\n", info.GetName(), info.GetAddress().String())
+		return nil
+	},
+}
+
+// keysList represents the keys list command
+var keysList = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "List all the keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, _, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		infos, err := keybase.List()
+		if err != nil {
+			return fmt.Errorf("failed to list keys: %w", err)
+		}
+
+		for _, info := range infos {
+			// the keyring lists the keys it failed to read without a public key
+			if info.GetPubKey() == nil {
+				fmt.Printf("%s\t<unreadable>\n", info.GetName())
+				continue
+			}
+			fmt.Printf("%s\t%s\n", info.GetName(), info.GetAddress().String())
+		}
+		return nil
+	},
+}
+
+// keyInfo is the keys show command output
+type keyInfo struct {
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	AccountNumber *uint64   `json:"account_number,omitempty"`
+	Sequence      *uint64   `json:"sequence,omitempty"`
+	Balance       sdk.Coins `json:"balance"`
+}
+
+// keysShow represents the keys show command
+var keysShow = &cobra.Command{
+	Use:   "show [name]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Show the key address, its on-chain account number, sequence and balance (RELAYER_NEUTRON_CHAIN_SIGN_KEY_NAME by default)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, cfg, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		name := cfg.SignKeyName
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			return fmt.Errorf("key name is not provided and RELAYER_NEUTRON_CHAIN_SIGN_KEY_NAME is not set")
+		}
+
+		info, err := keybase.Key(name)
+		if err != nil {
+			return fmt.Errorf("failed to get key %s: %w", name, err)
+		}
+		address := info.GetAddress().String()
+
+		client, err := newNeutronRPCClient(cfg)
+		if err != nil {
+			return err
+		}
+
+		result := keyInfo{Name: name, Address: address, Balance: sdk.NewCoins()}
+		// an account only exists on chain after it received some funds
+		account, err := submit.QueryAccount(cmd.Context(), client, address)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get account %s, the account might not be funded yet: %s\n", address, err)
+		} else {
+			result.AccountNumber = &account.AccountNumber
+			result.Sequence = &account.Sequence
+		}
+
+		result.Balance, err = submit.QueryBalances(cmd.Context(), client, address)
+		if err != nil {
+			return fmt.Errorf("failed to get balance of %s: %w", address, err)
+		}
+
+		var response bytes.Buffer
+		encoder := json.NewEncoder(&response)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode key info: %w", err)
+		}
+
+		fmt.Printf("Key:\n%s\n", response.String())
+		return nil
+	},
+}
+
+// keysDelete represents the keys delete command
+var keysDelete = &cobra.Command{
+	Use:   "delete <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Delete the key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, _, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		if _, err := keybase.Key(args[0]); err != nil {
+			return fmt.Errorf("failed to get key %s: %w", args[0], err)
+		}
+
+		yes, err := cmd.Flags().GetBool(YesFlagName)
+		if err != nil {
+			return err
+		}
+		if !yes {
+			confirmed, err := input.GetConfirmation(fmt.Sprintf("Key %s will be deleted. Continue?", args[0]),
+				bufio.NewReader(os.Stdin), os.Stderr)
+			if err != nil {
+				return fmt.Errorf("failed to read confirmation: %w", err)
+			}
+			if !confirmed {
+				fmt.Println("Key deletion aborted")
+				return nil
+			}
+		}
+
+		if err := keybase.Delete(args[0]); err != nil {
+			return fmt.Errorf("failed to delete key %s: %w", args[0], err)
+		}
+
+		fmt.Printf("Key %s deleted\n", args[0])
+		return nil
+	},
+}
+
+// keysExport represents the keys export command
+var keysExport = &cobra.Command{
+	Use:   "export <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Export the private key in ASCII-armored encrypted format",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keybase, _, err := openKeybase(cmd)
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := input.GetPassword("Enter passphrase to encrypt the exported key:", bufio.NewReader(os.Stdin))
+		if err != nil {
+			return fmt.Errorf("failed to read passphrase: %w", err)
+		}
+
+		armor, err := keybase.ExportPrivKeyArmor(args[0], passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to export key %s: %w", args[0], err)
+		}
+
+		fmt.Println(armor)
+		return nil
+	},
+}
+
+// openKeybase opens the keyring the relayer signs transactions with
+func openKeybase(cmd *cobra.Command) (keyring.Keyring, config.NeutronChainConfig, error) {
+	cfg, err := config.NewNeutronChainKeysConfig()
+	if err != nil {
+		return nil, cfg, fmt.Errorf("cannot initialize keys config: %w", err)
+	}
+
+	chainID, err := cmd.Flags().GetString(ChainIDFlagName)
+	if err != nil {
+		return nil, cfg, err
+	}
+	if chainID == "" {
+		chainID, err = queryChainID(cmd.Context(), cfg)
+		if err != nil {
+			return nil, cfg, fmt.Errorf("failed to get neutron chain id, consider setting it with --%s: %w", ChainIDFlagName, err)
+		}
+	}
+
+	keybase, err := submit.NewKeybase(chainID, cfg)
+	if err != nil {
+		return nil, cfg, fmt.Errorf("cannot initialize keybase: %w", err)
+	}
+
+	return keybase, cfg, nil
+}
+
+func queryChainID(ctx context.Context, cfg config.NeutronChainConfig) (string, error) {
+	client, err := newNeutronRPCClient(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get neutron chain status: %w", err)
+	}
+
+	return status.NodeInfo.Network, nil
+}
+
+func newNeutronRPCClient(cfg config.NeutronChainConfig) (*rpcclienthttp.HTTP, error) {
+	if strings.TrimSpace(cfg.RPCAddr) == "" {
+		return nil, fmt.Errorf("RELAYER_NEUTRON_CHAIN_RPC_ADDR is not set")
+	}
+
+	client, err := raw.NewRPCClient(cfg.RPCAddr, cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create neutron client: %w", err)
+	}
+
+	return client, nil
+}
@@ -24,6 +24,9 @@ var QueryCmd = &cobra.Command{
 func init() {
 	QueryCmd.PersistentFlags().StringVarP(&urlICQ, UrlFlagName, "u", "http://localhost:9999", "server url")
 	QueryCmd.AddCommand(UnsuccessfulTxs)
+	QueryCmd.AddCommand(UnsuccessfulKVs)
+	QueryCmd.AddCommand(QuorumIncidents)
+	QueryCmd.AddCommand(HarvestedDeposits)
 	rootCmd.AddCommand(QueryCmd)
 }
 
@@ -60,3 +63,105 @@ var UnsuccessfulTxs = &cobra.Command{
 		return nil
 	},
 }
+
+// UnsuccessfulKVs represents the unsuccessful-kvs command
+var UnsuccessfulKVs = &cobra.Command{
+	Use:   "unsuccessful-kvs",
+	Short: "Query KV queries with unsuccessfully submitted results",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := cmd.Flags().GetString(UrlFlagName)
+		if err != nil {
+			return err
+		}
+
+		client, err := icqhttp.NewICQClient(url)
+		if err != nil {
+			return fmt.Errorf("failed to get new icq client: %w", err)
+		}
+
+		kvs, err := client.GetUnsuccessfulKVs()
+		if err != nil {
+			return fmt.Errorf("failed to get unsuccessful kvs: %w", err)
+		}
+
+		var response bytes.Buffer
+		encoder := json.NewEncoder(&response)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(kvs)
+		if err != nil {
+			return fmt.Errorf("failed to encode unsuccessful kvs: %w", err)
+		}
+
+		fmt.Printf("Unsuccessful kvs:\n%s\n", response.String())
+
+		return nil
+	},
+}
+
+// QuorumIncidents represents the quorum-incidents command
+var QuorumIncidents = &cobra.Command{
+	Use:   "quorum-incidents",
+	Short: "Query recorded disagreements between target chain nodes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := cmd.Flags().GetString(UrlFlagName)
+		if err != nil {
+			return err
+		}
+
+		client, err := icqhttp.NewICQClient(url)
+		if err != nil {
+			return fmt.Errorf("failed to get new icq client: %w", err)
+		}
+
+		incidents, err := client.GetQuorumIncidents()
+		if err != nil {
+			return fmt.Errorf("failed to get quorum incidents: %w", err)
+		}
+
+		var response bytes.Buffer
+		encoder := json.NewEncoder(&response)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(incidents)
+		if err != nil {
+			return fmt.Errorf("failed to encode quorum incidents: %w", err)
+		}
+
+		fmt.Printf("Quorum incidents:\n%s\n", response.String())
+
+		return nil
+	},
+}
+
+// HarvestedDeposits represents the harvested-deposits command
+var HarvestedDeposits = &cobra.Command{
+	Use:   "harvested-deposits",
+	Short: "Query recorded deposits collected from timed out queries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, err := cmd.Flags().GetString(UrlFlagName)
+		if err != nil {
+			return err
+		}
+
+		client, err := icqhttp.NewICQClient(url)
+		if err != nil {
+			return fmt.Errorf("failed to get new icq client: %w", err)
+		}
+
+		deposits, err := client.GetHarvestedDeposits()
+		if err != nil {
+			return fmt.Errorf("failed to get harvested deposits: %w", err)
+		}
+
+		var response bytes.Buffer
+		encoder := json.NewEncoder(&response)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(deposits)
+		if err != nil {
+			return fmt.Errorf("failed to encode harvested deposits: %w", err)
+		}
+
+		fmt.Printf("Harvested deposits:\n%s\n", response.String())
+
+		return nil
+	},
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	neutronapp "github.com/neutron-org/neutron/app"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	nlogger "github.com/neutron-org/neutron-logger"
+	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	"github.com/neutron-org/neutron-query-relayer/internal/remotesigner"
+	"github.com/neutron-org/neutron-query-relayer/internal/submit"
+)
+
+// RemoteSignerCmd represents the remote-signer command
+var RemoteSignerCmd = &cobra.Command{
+	Use:   "remote-signer",
+	Short: "Reference remote signer which signs the relayer transactions with a key it keeps",
+}
+
+// remoteSignerStart represents the remote-signer start command
+var remoteSignerStart = &cobra.Command{
+	Use:   "start",
+	Short: "Start the remote signer server",
+	Run: func(cmd *cobra.Command, args []string) {
+		startRemoteSigner()
+	},
+}
+
+func init() {
+	RemoteSignerCmd.AddCommand(remoteSignerStart)
+	rootCmd.AddCommand(RemoteSignerCmd)
+}
+
+func startRemoteSigner() {
+	// set global values for prefixes for cosmos-sdk when parsing addresses and so on
+	globalCfg := neutronapp.GetDefaultConfig()
+	globalCfg.Seal()
+
+	logRegistry, err := nlogger.NewRegistry(remotesigner.ServerContext)
+	if err != nil {
+		log.Fatalf("couldn't initialize loggers registry: %s", err)
+	}
+	logger := logRegistry.Get(remotesigner.ServerContext)
+
+	cfg, err := config.NewRemoteSignerConfig()
+	if err != nil {
+		logger.Fatal("cannot initialize remote signer config", zap.Error(err))
+	}
+
+	keysCfg, err := config.NewNeutronChainKeysConfig()
+	if err != nil {
+		logger.Fatal("cannot initialize keys config", zap.Error(err))
+	}
+
+	keybase, err := submit.NewKeybase(cfg.ChainID, keysCfg)
+	if err != nil {
+		logger.Fatal("cannot initialize keybase", zap.Error(err))
+	}
+
+	signer, err := submit.NewKeyringSigner(keybase, keysCfg.SignKeyName)
+	if err != nil {
+		logger.Fatal("cannot initialize keyring signer", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		<-sigs
+		cancel()
+	}()
+
+	maxFee, err := sdk.ParseCoinsNormalized(cfg.MaxFee)
+	if err != nil {
+		logger.Fatal("cannot parse remote signer max fee", zap.Error(err))
+	}
+
+	tlsConfig, err := remotesigner.NewServerTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+	if err != nil {
+		logger.Fatal("cannot initialize remote signer tls config", zap.Error(err))
+	}
+	if cfg.AuthToken == "" && cfg.TLSClientCAFile == "" {
+		logger.Warn("remote signer authentication is disabled, anyone able to reach the listen address can sign transactions")
+	}
+
+	logger.Info("remote signer starts...",
+		zap.String("listen_addr", cfg.ListenAddr),
+		zap.String("chain_id", cfg.ChainID),
+		zap.Bool("tls", tlsConfig != nil),
+	)
+	server := remotesigner.NewServer(signer, cfg.ChainID, cfg.AllowedMsgTypes, maxFee, cfg.AuthToken, logger)
+	if err := server.Run(ctx, cfg.ListenAddr, tlsConfig); err != nil {
+		logger.Fatal("remote signer failed", zap.Error(err))
+	}
+}
@@ -60,6 +60,14 @@ func startRelayer() {
 		app.TxSubmitCheckerContext,
 		app.TrustedHeadersFetcherContext,
 		app.KVProcessorContext,
+		app.QuorumCheckerContext,
+		app.GrantsCheckerContext,
+		app.ElectorContext,
+		app.JanitorContext,
+		app.NeutronQuerierContext,
+		app.UpgradeWatcherContext,
+		app.ClientWatchdogContext,
+		app.ChainMonitorContext,
 		icqhttp.MonitoringLoggerContext,
 	)
 	if err != nil {
@@ -89,17 +97,18 @@ func startRelayer() {
 
 	var (
 		queriesTasksQueue      = make(chan neutrontypes.RegisteredQuery, cfg.QueriesTaskQueueCapacity)
-		submittedTxsTasksQueue = make(chan relay.PendingSubmittedTxInfo)
+		submittedTxsTasksQueue = make(chan relay.PendingSubmittedTxInfo, cfg.QueriesTaskQueueCapacity)
+		tasksFeedbackQueue     = make(chan relay.TaskFeedback, cfg.QueriesTaskQueueCapacity)
 	)
 
-	subscriber, err := app.NewDefaultSubscriber(cfg, logRegistry)
+	deps, err := app.NewDefaultDependencyContainer(ctx, cfg, logRegistry, storage)
 	if err != nil {
-		logger.Fatal("Failed to get NewDefaultSubscriber", zap.Error(err))
+		logger.Fatal("failed to initialize dependency container", zap.Error(err))
 	}
 
-	deps, err := app.NewDefaultDependencyContainer(ctx, cfg, logRegistry, storage)
+	subscriber, err := app.NewDefaultSubscriber(cfg, logRegistry, storage, deps)
 	if err != nil {
-		logger.Fatal("failed to initialize dependency container", zap.Error(err))
+		logger.Fatal("Failed to get NewDefaultSubscriber", zap.Error(err))
 	}
 
 	relayer, err := app.NewDefaultRelayer(cfg, logRegistry, storage, deps)
@@ -107,16 +116,35 @@ func startRelayer() {
 		logger.Fatal("Failed to get NewDefaultRelayer", zap.Error(err))
 	}
 
-	txSubmitChecker, err := app.NewDefaultTxSubmitChecker(cfg, logRegistry, storage)
+	txSubmitChecker, err := app.NewDefaultTxSubmitChecker(cfg, logRegistry, storage, deps)
 	if err != nil {
 		logger.Fatal("Failed to get NewDefaultTxSubmitChecker", zap.Error(err))
 	}
 
+	elector, err := app.NewDefaultElector(cfg, logRegistry)
+	if err != nil {
+		logger.Fatal("Failed to get NewDefaultElector", zap.Error(err))
+	}
+
+	// elected is closed once the instance becomes the leader, the standby keeps the caches and the connections
+	// warm and starts processing the queries on takeover
+	elected := make(chan struct{})
+	// the elector outlives the other goroutines to keep the lease until the submissions stop
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+
+		if err := elector.Run(ctx, elected); err != nil {
+			logger.Error("Elector exited with an error", zap.Error(err))
+			cancel()
+		}
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
-		err := icqhttp.Run(ctx, logRegistry, storage, deps.GetTxProcessor(), submittedTxsTasksQueue, cfg.ListenAddr)
+		err := icqhttp.Run(ctx, logRegistry, storage, deps.GetTxProcessor(), deps.GetKvProcessor(), deps.GetErrorClassifier(), elector, deps.GetChainHealth(), submittedTxsTasksQueue, cfg.ListenAddr)
 		if err != nil {
 			logger.Error("WebServer exited with an error", zap.Error(err))
 			cancel()
@@ -127,6 +155,9 @@ func startRelayer() {
 	go func() {
 		defer wg.Done()
 
+		if !awaitLeadership(ctx, elected) {
+			return
+		}
 		err := txSubmitChecker.Run(ctx, submittedTxsTasksQueue)
 		if err != nil {
 			logger.Error("TxSubmitChecker exited with an error", zap.Error(err))
@@ -134,12 +165,83 @@ func startRelayer() {
 		}
 	}()
 
+	if grantsChecker := deps.GetGrantsChecker(); grantsChecker != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := grantsChecker.Run(ctx, cfg.GrantsCheckPeriod); err != nil {
+				logger.Error("GrantsChecker exited with an error", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
+	// the standby monitors the target chain as well to report its health
+	if chainMonitor := deps.GetChainMonitor(); chainMonitor != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := chainMonitor.Run(ctx, cfg.TargetChain.HaltCheckPeriod); err != nil {
+				logger.Error("ChainMonitor exited with an error", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
+	// the standby watches the upgrades as well to be ready to take over
+	if upgradeWatcher := deps.GetUpgradeWatcher(); upgradeWatcher != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := upgradeWatcher.Run(ctx, cfg.TargetChain.UpgradeCheckPeriod); err != nil {
+				logger.Error("UpgradeWatcher exited with an error", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
+	if clientWatchdog := deps.GetClientWatchdog(); clientWatchdog != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if !awaitLeadership(ctx, elected) {
+				return
+			}
+			if err := clientWatchdog.Run(ctx, cfg.ClientCheckPeriod); err != nil {
+				logger.Error("ClientWatchdog exited with an error", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
+	if janitor := deps.GetJanitor(); janitor != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if !awaitLeadership(ctx, elected) {
+				return
+			}
+			if err := janitor.Run(ctx, cfg.HarvestPeriod); err != nil {
+				logger.Error("Janitor exited with an error", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
-		// The subscriber writes to the tasks queue.
-		if err := subscriber.Subscribe(ctx, queriesTasksQueue); err != nil {
+		if !awaitLeadership(ctx, elected) {
+			return
+		}
+		// The subscriber writes to the tasks queue and reads from the tasks feedback queue.
+		if err := subscriber.Subscribe(ctx, queriesTasksQueue, tasksFeedbackQueue); err != nil {
 			logger.Error("Subscriber exited with an error", zap.Error(err))
 			cancel()
 		}
@@ -149,8 +251,11 @@ func startRelayer() {
 	go func() {
 		defer wg.Done()
 
-		// The relayer reads from the tasks queue.
-		if err := relayer.Run(ctx, queriesTasksQueue, submittedTxsTasksQueue); err != nil {
+		if !awaitLeadership(ctx, elected) {
+			return
+		}
+		// The relayer reads from the tasks queue and writes to the tasks feedback queue.
+		if err := relayer.Run(ctx, queriesTasksQueue, submittedTxsTasksQueue, tasksFeedbackQueue, elector.Deposed()); err != nil {
 			logger.Error("Relayer exited with an error", zap.Error(err))
 			cancel()
 		}
@@ -167,4 +272,17 @@ func startRelayer() {
 	}()
 
 	wg.Wait()
+	// the task in progress is drained and the rebroadcasts are done by now, so a standby can take over
+	elector.Release()
+	<-electorDone
+}
+
+// awaitLeadership blocks until the relayer instance becomes the leader, it returns false if the ctx is cancelled first
+func awaitLeadership(ctx context.Context, elected <-chan struct{}) bool {
+	select {
+	case <-elected:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
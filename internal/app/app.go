@@ -3,28 +3,40 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/kvprocessor"
 	"github.com/neutron-org/neutron-query-relayer/internal/txprocessor"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
 	cosmosrelayer "github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/storage"
 
 	"time"
 
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	rpcclienthttp "github.com/tendermint/tendermint/rpc/client/http"
 	"go.uber.org/zap"
 
 	nlogger "github.com/neutron-org/neutron-logger"
+	"github.com/neutron-org/neutron-query-relayer/internal/chainmonitor"
 	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	"github.com/neutron-org/neutron-query-relayer/internal/grants"
+	"github.com/neutron-org/neutron-query-relayer/internal/leader"
+	"github.com/neutron-org/neutron-query-relayer/internal/neutronquerier"
+	"github.com/neutron-org/neutron-query-relayer/internal/quorum"
 	"github.com/neutron-org/neutron-query-relayer/internal/raw"
 	"github.com/neutron-org/neutron-query-relayer/internal/registry"
 	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+	"github.com/neutron-org/neutron-query-relayer/internal/submit"
 	"github.com/neutron-org/neutron-query-relayer/internal/subscriber"
 	relaysubscriber "github.com/neutron-org/neutron-query-relayer/internal/subscriber"
-	"github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client/query"
 	"github.com/neutron-org/neutron-query-relayer/internal/txsubmitchecker"
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
@@ -47,8 +59,21 @@ const (
 	TxSubmitCheckerContext       = "tx_submit_checker"
 	TrustedHeadersFetcherContext = "trusted_headers_fetcher"
 	KVProcessorContext           = "kv_processor"
+	QuorumCheckerContext         = "quorum_checker"
+	GrantsCheckerContext         = "grants_checker"
+	ElectorContext               = "elector"
+	JanitorContext               = "janitor"
+	NeutronQuerierContext        = "neutron_querier"
+	UpgradeWatcherContext        = "upgrade_watcher"
+	ClientWatchdogContext        = "client_watchdog"
+	ChainMonitorContext          = "chain_monitor"
 )
 
+// LeaseBackendFile keeps the lease in a file on a volume shared by the relayer instances. It's the only lease
+// backend: the storage is a local leveldb, so there is no shared SQL row to keep the lease in, and an on-chain
+// heartbeat would need a contract on Neutron to arbitrate between the instances.
+const LeaseBackendFile = "file"
+
 // retries configuration for fetching connection info
 var (
 	rtyAtt = retry.Attempts(uint(5))
@@ -56,20 +81,48 @@ var (
 	rtyErr = retry.LastErrorOnly(true)
 )
 
-func NewDefaultSubscriber(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry) (relay.Subscriber, error) {
-	watchedMsgTypes := []neutrontypes.InterchainQueryType{neutrontypes.InterchainQueryTypeKV}
+// NewDefaultSubscriber returns the subscriber of the queries
+func NewDefaultSubscriber(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry, storage relay.Storage,
+	deps *DependencyContainer) (relay.Subscriber, error) {
+	// the results are submitted on behalf of the authz granter if it's set
+	submitterAddr := deps.GetSenderAddr()
+	if cfg.AuthzGranter != "" {
+		submitterAddr = cfg.AuthzGranter
+	}
+
+	// the codec decodes the query result submissions of the competing relayers
+	codec := raw.MakeCodecDefault()
+	neutrontypes.RegisterInterfaces(codec.InterfaceRegistry)
+	txConfig := authtx.NewTxConfig(codec.Marshaller, authtx.DefaultSignModes)
+
+	signMode, err := submit.ParseSignMode(cfg.NeutronChain.SignModeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign mode: %w", err)
+	}
+
+	// the KV query results are submitted along with MsgUpdateClient which can be signed in SIGN_MODE_DIRECT only
+	var watchedMsgTypes []neutrontypes.InterchainQueryType
+	if signMode == signing.SignMode_SIGN_MODE_DIRECT {
+		watchedMsgTypes = append(watchedMsgTypes, neutrontypes.InterchainQueryTypeKV)
+	}
 	if cfg.AllowTxQueries {
 		watchedMsgTypes = append(watchedMsgTypes, neutrontypes.InterchainQueryTypeTX)
 	}
 
 	subscriber, err := relaysubscriber.NewSubscriber(
 		&subscriber.SubscriberConfig{
-			RPCAddress:   cfg.NeutronChain.RPCAddr,
-			RESTAddress:  cfg.NeutronChain.RESTAddr,
-			Timeout:      cfg.NeutronChain.Timeout,
-			ConnectionID: cfg.NeutronChain.ConnectionID,
-			WatchedTypes: watchedMsgTypes,
-			Registry:     registry.New(cfg.Registry),
+			RPCAddress:           cfg.NeutronChain.RPCAddr,
+			Querier:              deps.GetNeutronQuerier(),
+			Timeout:              cfg.NeutronChain.Timeout,
+			ConnectionID:         cfg.NeutronChain.ConnectionID,
+			WatchedTypes:         watchedMsgTypes,
+			Registry:             registry.New(cfg.Registry),
+			TaskRetryBaseDelay:   cfg.TaskRetryBaseDelay,
+			ReconciliationPeriod: cfg.ReconciliationPeriod,
+			SubmitterAddr:        submitterAddr,
+			TxDecoder:            txConfig.TxDecoder(),
+			CompetitorLagBlocks:  cfg.CompetitorLagBlocks,
+			Storage:              storage,
 		},
 		logRegistry.Get(SubscriberContext),
 	)
@@ -81,7 +134,7 @@ func NewDefaultSubscriber(cfg config.NeutronQueryRelayerConfig, logRegistry *nlo
 }
 
 func NewDefaultTxSubmitChecker(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry,
-	storage relay.Storage) (relay.TxSubmitChecker, error) {
+	storage relay.Storage, deps *DependencyContainer) (relay.TxSubmitChecker, error) {
 	neutronClient, err := raw.NewRPCClient(cfg.NeutronChain.RPCAddr, cfg.NeutronChain.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create NewRPCClient: %w", err)
@@ -91,9 +144,145 @@ func NewDefaultTxSubmitChecker(cfg config.NeutronQueryRelayerConfig, logRegistry
 		storage,
 		neutronClient,
 		logRegistry.Get(TxSubmitCheckerContext),
+		deps.GetSenderAddr(),
+		deps.GetGasObserver(),
+		deps.GetTxProcessor(),
+		deps.GetKvProcessor(),
+		cfg.ConfirmationDeadlineBlocks,
+		cfg.ConfirmationPollPeriod,
 	), nil
 }
 
+// NewDefaultQuorumChecker returns a quorum checker for the target chain nodes configured in cfg.
+// Returns nil checker if no quorum nodes are configured, meaning the quorum mode is disabled.
+func NewDefaultQuorumChecker(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry,
+	storage relay.Storage) (*quorum.Checker, error) {
+	if len(cfg.TargetChain.QuorumRPCAddrs) == 0 {
+		return nil, nil
+	}
+
+	nodes := make([]quorum.Node, 0, len(cfg.TargetChain.QuorumRPCAddrs))
+	for _, addr := range cfg.TargetChain.QuorumRPCAddrs {
+		client, err := raw.NewRPCClient(addr, cfg.TargetChain.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NewRPCClient for quorum node %s: %w", addr, err)
+		}
+		nodes = append(nodes, quorum.Node{Addr: addr, Client: client})
+	}
+
+	return quorum.NewChecker(nodes, cfg.TargetChain.QuorumThreshold, storage, logRegistry.Get(QuorumCheckerContext))
+}
+
+// NewDefaultGrantsChecker returns nil if neither fee grants nor authz are configured. The grants are
+// checked right away, so the relayer doesn't start with a missing grant.
+func NewDefaultGrantsChecker(ctx context.Context, cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry,
+	neutronClient rpcclient.Client, codec raw.Codec, txSender *submit.TxSender) (*grants.Checker, error) {
+	if cfg.FeeGranter == "" && !cfg.FeeGrantByOwner && cfg.AuthzGranter == "" {
+		return nil, nil
+	}
+
+	grantee, err := txSender.SenderAddr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender address: %w", err)
+	}
+
+	checker := grants.NewChecker(
+		neutronClient,
+		codec.InterfaceRegistry,
+		grantee,
+		cfg.FeeGranter,
+		cfg.FeeGrantByOwner,
+		cfg.AuthzGranter,
+		cfg.Registry.Addresses,
+		logRegistry.Get(GrantsCheckerContext),
+	)
+	if err := checker.Check(ctx); err != nil {
+		return nil, fmt.Errorf("grants check failed: %w", err)
+	}
+
+	return checker, nil
+}
+
+// NewDefaultNeutronQuerier returns the querier making the gRPC queries to the Neutron RPC node, the queries
+// fall back to the REST gateway if its address is configured
+func NewDefaultNeutronQuerier(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry,
+	neutronClient rpcclient.Client) (relay.NeutronQuerier, error) {
+	abciQuerier := neutronquerier.NewABCIQuerier(neutronClient)
+	if cfg.NeutronChain.RESTAddr == "" {
+		return abciQuerier, nil
+	}
+
+	restClient, err := raw.NewRESTClient(cfg.NeutronChain.RESTAddr, cfg.NeutronChain.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NewRESTClient: %w", err)
+	}
+
+	return neutronquerier.NewFallbackQuerier(
+		abciQuerier,
+		neutronquerier.NewRESTQuerier(restClient),
+		logRegistry.Get(NeutronQuerierContext),
+	), nil
+}
+
+// NewDefaultChainMonitor returns the monitor of the target chain comparing the target node with the quorum
+// nodes, or nil if the monitoring is disabled
+func NewDefaultChainMonitor(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry,
+	targetClient rpcclient.Client) (*chainmonitor.Monitor, error) {
+	if cfg.TargetChain.HaltCheckPeriod <= 0 {
+		return nil, nil
+	}
+	if cfg.TargetChain.MaxBlockAge <= 0 {
+		return nil, fmt.Errorf("max block age must be positive, got %s", cfg.TargetChain.MaxBlockAge)
+	}
+
+	peers := make([]rpcclient.Client, 0, len(cfg.TargetChain.QuorumRPCAddrs))
+	for _, addr := range cfg.TargetChain.QuorumRPCAddrs {
+		client, err := raw.NewRPCClient(addr, cfg.TargetChain.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NewRPCClient for target node %s: %w", addr, err)
+		}
+		peers = append(peers, client)
+	}
+
+	return chainmonitor.NewMonitor(
+		targetClient,
+		peers,
+		cfg.TargetChain.MaxBlockAge,
+		cfg.TargetChain.MaxHeightLag,
+		logRegistry.Get(ChainMonitorContext),
+	), nil
+}
+
+// NewDefaultElector returns the elector of the relayer instance, the instance is always the leader if no lease
+// backend is configured
+func NewDefaultElector(cfg config.NeutronQueryRelayerConfig, logRegistry *nlogger.Registry) (*leader.Elector, error) {
+	var lease relay.Lease
+	switch cfg.LeaseBackend {
+	case "":
+	case LeaseBackendFile:
+		if cfg.LeaseFile == "" {
+			return nil, fmt.Errorf("lease file must be set for the %s lease backend", LeaseBackendFile)
+		}
+		lease = leader.NewFileLease(cfg.LeaseFile)
+	default:
+		return nil, fmt.Errorf("unsupported lease backend %s, only %s is supported", cfg.LeaseBackend, LeaseBackendFile)
+	}
+	if lease != nil && cfg.LeaseTimeout <= 0 {
+		return nil, fmt.Errorf("lease timeout must be positive, got %s", cfg.LeaseTimeout)
+	}
+
+	holder := cfg.LeaseHolder
+	if holder == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for the lease holder: %w", err)
+		}
+		holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return leader.NewElector(lease, holder, cfg.LeaseTimeout, logRegistry.Get(ElectorContext)), nil
+}
+
 // NewDefaultRelayer returns a relayer built with cfg.
 func NewDefaultRelayer(
 	cfg config.NeutronQueryRelayerConfig,
@@ -103,7 +292,7 @@ func NewDefaultRelayer(
 ) (*relay.Relayer, error) {
 	var (
 		txProcessor = txprocessor.NewTxProcessor(
-			deps.GetTrustedHeaderFetcher(), storage, deps.GetProofSubmitter(), logRegistry.Get(TxProcessorContext), cfg.CheckSubmittedTxStatusDelay, cfg.IgnoreErrorsRegex)
+			deps.GetTrustedHeaderFetcher(), storage, deps.GetProofSubmitter(), logRegistry.Get(TxProcessorContext), deps.GetErrorClassifier(), deps.GetProofVerifier())
 		kvProcessor = kvprocessor.NewKVProcessor(
 			deps.GetTrustedHeaderFetcher(),
 			deps.GetTargetQuerier(),
@@ -111,8 +300,9 @@ func NewDefaultRelayer(
 			logRegistry.Get(KVProcessorContext),
 			deps.GetProofSubmitter(),
 			storage,
-			deps.GetTargetChain(),
 			deps.GetNeutronChain(),
+			deps.GetProofVerifier(),
+			deps.GetErrorClassifier(),
 		)
 		relayer = relay.NewRelayer(
 			cfg,
@@ -121,6 +311,7 @@ func NewDefaultRelayer(
 			txProcessor,
 			kvProcessor,
 			deps.GetTargetChain(),
+			deps.GetChainHealth(),
 			logRegistry.Get(RelayerContext),
 		)
 	)
@@ -145,18 +336,11 @@ func loadChains(
 	cfg config.NeutronQueryRelayerConfig,
 	logRegistry *nlogger.Registry,
 	connParams *connectionParams,
+	keybase keyring.Keyring,
 ) (neutronChain *cosmosrelayer.Chain, targetChain *cosmosrelayer.Chain, err error) {
-	targetChain, err = relay.GetTargetChain(logRegistry.Get(TargetChainProviderContext), cfg.TargetChain, connParams.targetChainID)
+	targetChain, err = loadTargetChain(cfg, logRegistry, connParams, connParams.targetChainID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load target chain from env: %w", err)
-	}
-
-	if err := targetChain.AddPath(connParams.targetClientID, connParams.targetConnectionID); err != nil {
-		return nil, nil, fmt.Errorf("failed to AddPath to source chain: %w", err)
-	}
-
-	if err := targetChain.ChainProvider.Init(); err != nil {
-		return nil, nil, fmt.Errorf("failed to Init source chain provider: %w", err)
+		return nil, nil, err
 	}
 
 	neutronChain, err = relay.GetNeutronChain(logRegistry.Get(NeutronChainProviderContext), cfg.NeutronChain, connParams.neutronChainID)
@@ -172,9 +356,40 @@ func loadChains(
 		return nil, nil, fmt.Errorf("failed to Init source chain provider: %w", err)
 	}
 
+	// The provider opens its own keyring with no way to unlock it non-interactively, so we make it
+	// share the already opened one.
+	neutronProvider, ok := neutronChain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to cast neutron ChainProvider to concrete type (cosmos.CosmosProvider)")
+	}
+	neutronProvider.Keybase = keybase
+
 	return neutronChain, targetChain, nil
 }
 
+// loadTargetChain loads the target chain with the chainID, the chain ID changes on the target chain upgrades
+func loadTargetChain(
+	cfg config.NeutronQueryRelayerConfig,
+	logRegistry *nlogger.Registry,
+	connParams *connectionParams,
+	chainID string,
+) (*cosmosrelayer.Chain, error) {
+	targetChain, err := relay.GetTargetChain(logRegistry.Get(TargetChainProviderContext), cfg.TargetChain, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target chain from env: %w", err)
+	}
+
+	if err := targetChain.AddPath(connParams.targetClientID, connParams.targetConnectionID); err != nil {
+		return nil, fmt.Errorf("failed to AddPath to source chain: %w", err)
+	}
+
+	if err := targetChain.ChainProvider.Init(); err != nil {
+		return nil, fmt.Errorf("failed to Init source chain provider: %w", err)
+	}
+
+	return targetChain, nil
+}
+
 type connectionParams struct {
 	neutronChainID  string
 	neutronClientID string
@@ -184,12 +399,7 @@ type connectionParams struct {
 	targetConnectionID string
 }
 
-func loadConnParams(ctx context.Context, neutronClient, targetClient *rpcclienthttp.HTTP, neutronRestAddress string, neutronConnectionId string, logger *zap.Logger) (*connectionParams, error) {
-	restClient, err := raw.NewRESTClient(neutronRestAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get newRESTClient: %w", err)
-	}
-
+func loadConnParams(ctx context.Context, neutronClient, targetClient *rpcclienthttp.HTTP, neutronQuerier relay.NeutronQuerier, neutronConnectionId string, logger *zap.Logger) (*connectionParams, error) {
 	targetStatus, err := targetClient.Status(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch target chain status: %w", err)
@@ -200,23 +410,20 @@ func loadConnParams(ctx context.Context, neutronClient, targetClient *rpcclienth
 		return nil, fmt.Errorf("failed to fetch neutron chain status: %w", err)
 	}
 
-	var queryResponse *query.IbcCoreConnectionV1ConnectionOK
+	var connection *connectiontypes.ConnectionEnd
 	if err := retry.Do(func() error {
 		var err error
 
-		queryResponse, err = restClient.Query.IbcCoreConnectionV1Connection(&query.IbcCoreConnectionV1ConnectionParams{
-			ConnectionID: neutronConnectionId,
-			Context:      ctx,
-		})
+		connection, err = neutronQuerier.Connection(ctx, neutronConnectionId)
 		if err != nil {
 			return err
 		}
 
-		if queryResponse.GetPayload().Connection.Counterparty.ConnectionID == "" {
+		if connection.Counterparty.ConnectionId == "" {
 			return fmt.Errorf("empty target connection ID")
 		}
 
-		if queryResponse.GetPayload().Connection.Counterparty.ClientID == "" {
+		if connection.Counterparty.ClientId == "" {
 			return fmt.Errorf("empty target client ID")
 		}
 
@@ -225,19 +432,15 @@ func loadConnParams(ctx context.Context, neutronClient, targetClient *rpcclienth
 		logger.Info(
 			"failed to query ibc connection info", zap.Error(err))
 	})); err != nil {
-		return nil, err
-	}
-
-	if err != nil {
 		return nil, fmt.Errorf("failed to query ibc connection info: %w", err)
 	}
 
 	connParams := connectionParams{
 		neutronChainID:     neutronStatus.NodeInfo.Network,
 		targetChainID:      targetStatus.NodeInfo.Network,
-		neutronClientID:    queryResponse.GetPayload().Connection.ClientID,
-		targetClientID:     queryResponse.GetPayload().Connection.Counterparty.ClientID,
-		targetConnectionID: queryResponse.GetPayload().Connection.Counterparty.ConnectionID,
+		neutronClientID:    connection.ClientId,
+		targetClientID:     connection.Counterparty.ClientId,
+		targetConnectionID: connection.Counterparty.ConnectionId,
 	}
 
 	logger.Info("loaded conn params",
@@ -2,15 +2,27 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	cosmosrelayer "github.com/cosmos/relayer/v2/relayer"
 
 	nlogger "github.com/neutron-org/neutron-logger"
+	"github.com/neutron-org/neutron-query-relayer/internal/chainmonitor"
+	"github.com/neutron-org/neutron-query-relayer/internal/chainupgrade"
+	"github.com/neutron-org/neutron-query-relayer/internal/clientwatchdog"
 	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	"github.com/neutron-org/neutron-query-relayer/internal/errorclassifier"
+	"github.com/neutron-org/neutron-query-relayer/internal/grants"
+	"github.com/neutron-org/neutron-query-relayer/internal/janitor"
 	"github.com/neutron-org/neutron-query-relayer/internal/kvprocessor"
+	"github.com/neutron-org/neutron-query-relayer/internal/proofverifier"
+	"github.com/neutron-org/neutron-query-relayer/internal/quorum"
 	"github.com/neutron-org/neutron-query-relayer/internal/raw"
 	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+	"github.com/neutron-org/neutron-query-relayer/internal/remotesigner"
 	"github.com/neutron-org/neutron-query-relayer/internal/submit"
 	"github.com/neutron-org/neutron-query-relayer/internal/tmquerier"
 	"github.com/neutron-org/neutron-query-relayer/internal/trusted_headers"
@@ -27,6 +39,16 @@ type DependencyContainer struct {
 	targetChain          *cosmosrelayer.Chain
 	neutronChain         *cosmosrelayer.Chain
 	targetQuerier        *tmquerier.Querier
+	proofVerifier        relay.ProofVerifier
+	grantsChecker        *grants.Checker
+	gasObserver          relay.GasObserver
+	errorClassifier      relay.ErrorClassifier
+	senderAddr           string
+	janitor              *janitor.Janitor
+	neutronQuerier       relay.NeutronQuerier
+	upgradeWatcher       *chainupgrade.Watcher
+	clientWatchdog       *clientwatchdog.Watchdog
+	chainMonitor         *chainmonitor.Monitor
 }
 
 func NewDefaultDependencyContainer(ctx context.Context,
@@ -43,27 +65,101 @@ func NewDefaultDependencyContainer(ctx context.Context,
 		return nil, fmt.Errorf("cannot create neutron client: %w", err)
 	}
 
-	connParams, err := loadConnParams(ctx, neutronClient, targetClient, cfg.NeutronChain.RESTAddr,
+	neutronQuerier, err := NewDefaultNeutronQuerier(cfg, logRegistry, neutronClient)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create neutron querier: %w", err)
+	}
+
+	connParams, err := loadConnParams(ctx, neutronClient, targetClient, neutronQuerier,
 		cfg.NeutronChain.ConnectionID, logRegistry.Get(AppContext))
 	if err != nil {
 		return nil, fmt.Errorf("cannot load network params: %w", err)
 	}
 
-	targetQuerier, err := tmquerier.NewQuerier(targetClient, connParams.targetChainID)
+	chainMonitor, err := NewDefaultChainMonitor(cfg, logRegistry, targetClient)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create chain monitor: %w", err)
+	}
+
+	quorumChecker, err := NewDefaultQuorumChecker(cfg, logRegistry, storage)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create quorum checker: %w", err)
+	}
+
+	targetQuerier, err := tmquerier.NewQuerier(
+		targetClient,
+		connParams.targetChainID,
+		quorumChecker,
+		cfg.KvProofCacheHeights,
+		cfg.KvHeightAlignmentWindow,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("cannot connect to target chain: %w", err)
 	}
 
 	codec := raw.MakeCodecDefault()
-	keybase, err := submit.TestKeybase(connParams.neutronChainID, cfg.NeutronChain.HomeDir)
+	signMode, err := submit.ParseSignMode(cfg.NeutronChain.SignModeStr)
 	if err != nil {
-		return nil, fmt.Errorf("cannot initialize keybase: %w", err)
+		return nil, fmt.Errorf("invalid sign mode: %w", err)
+	}
+	if signMode != signing.SignMode_SIGN_MODE_DIRECT {
+		// the remote signer checks the SignDoc, and the MsgExec amino JSON needs the executed messages amino names
+		if cfg.NeutronChain.RemoteSignerAddr != "" {
+			return nil, fmt.Errorf("remote signer supports %s sign mode only", submit.SignModeDirect)
+		}
+		if cfg.AuthzGranter != "" {
+			return nil, fmt.Errorf("authz is supported in %s sign mode only", submit.SignModeDirect)
+		}
+		// MsgUpdateClient has no amino JSON representation, so the KV queries are not processed at all
+		if cfg.ClientCheckPeriod > 0 && cfg.ClientRefreshThreshold > 0 {
+			return nil, fmt.Errorf("client refresh is supported in %s sign mode only", submit.SignModeDirect)
+		}
+		if !cfg.AllowTxQueries {
+			return nil, fmt.Errorf("only TX queries are processed in %s sign mode, but they are not allowed", cfg.NeutronChain.SignModeStr)
+		}
+	}
+
+	var keybase keyring.Keyring
+	var signer submit.Signer
+	if cfg.NeutronChain.RemoteSignerAddr != "" {
+		var tlsConfig *tls.Config
+		tlsConfig, err = remotesigner.NewClientTLSConfig(
+			cfg.NeutronChain.RemoteSignerCAFile,
+			cfg.NeutronChain.RemoteSignerCertFile,
+			cfg.NeutronChain.RemoteSignerKeyFile,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create remote signer tls config: %w", err)
+		}
+		signer, err = remotesigner.NewClient(
+			cfg.NeutronChain.RemoteSignerAddr,
+			cfg.NeutronChain.Timeout,
+			cfg.NeutronChain.RemoteSignerAuthToken,
+			tlsConfig,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create remote signer client: %w", err)
+		}
+		keybase, err = remotesigner.NewPubKeyKeybase(ctx, signer, cfg.NeutronChain.SignKeyName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize remote signer keybase: %w", err)
+		}
+	} else {
+		keybase, err = submit.NewKeybase(connParams.neutronChainID, *cfg.NeutronChain)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize keybase: %w", err)
+		}
+		signer, err = submit.NewKeyringSigner(keybase, cfg.NeutronChain.SignKeyName)
+		if err != nil {
+			return nil, fmt.Errorf("sign key %s is not found in the %s keyring at %s: %w",
+				cfg.NeutronChain.SignKeyName, cfg.NeutronChain.KeyringBackend, cfg.NeutronChain.HomeDir, err)
+		}
 	}
 
 	txSender, err := submit.NewTxSender(ctx,
 		neutronClient,
 		codec.Marshaller,
-		keybase,
+		signer,
 		*cfg.NeutronChain,
 		logRegistry.Get(TxSenderContext),
 		connParams.neutronChainID)
@@ -71,16 +167,74 @@ func NewDefaultDependencyContainer(ctx context.Context,
 		return nil, fmt.Errorf("cannot create tx sender: %w", err)
 	}
 
-	neutronChain, targetChain, err := loadChains(cfg, logRegistry, connParams)
+	senderAddr, err := txSender.SenderAddr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender address: %w", err)
+	}
+
+	neutronChain, targetChain, err := loadChains(cfg, logRegistry, connParams, keybase)
 	if err != nil {
 		return nil, fmt.Errorf("failed to loadChains: %w", err)
 	}
 
-	proofSubmitter := submit.NewSubmitterImpl(txSender, cfg.AllowKVCallbacks, neutronChain.PathEnd.ClientID)
-	txQuerier := txquerier.NewTXQuerySrv(targetQuerier.Client)
+	grantsChecker, err := NewDefaultGrantsChecker(ctx, cfg, logRegistry, neutronClient, codec, txSender)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create grants checker: %w", err)
+	}
+	// a nil grantsProvider disables fee grants and authz
+	var grantsProvider relay.GrantsProvider
+	if grantsChecker != nil {
+		grantsProvider = grantsChecker
+	}
+
+	var depositsJanitor *janitor.Janitor
+	if cfg.HarvestDeposits {
+		if cfg.HarvestBatchSize == 0 {
+			return nil, fmt.Errorf("harvest batch size must be positive")
+		}
+		depositsJanitor = janitor.NewJanitor(neutronClient, neutronQuerier, txSender, storage, senderAddr, cfg.HarvestBatchSize,
+			cfg.HarvestOwnerDenyList, logRegistry.Get(JanitorContext))
+	}
+
+	errorClassifier, err := errorclassifier.NewClassifier(cfg.ErrorClasses, cfg.CriticalErrorsRegex, cfg.IgnoreErrorsRegex, cfg.RetryableErrorsRegex)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create error classifier: %w", err)
+	}
+
+	proofSubmitter := submit.NewSubmitterImpl(txSender, cfg.AllowKVCallbacks, neutronChain.PathEnd.ClientID, grantsProvider)
+	var txQuerierClient relay.ChainClient = targetQuerier.Client
+	if quorumChecker != nil {
+		txQuerierClient = quorum.NewChainClient(targetQuerier.Client, quorumChecker)
+	}
+	txQuerier := txquerier.NewTXQuerySrv(txQuerierClient)
 	trustedHeaderFetcher := trusted_headers.NewTrustedHeaderFetcher(neutronChain, targetChain, logRegistry.Get(TrustedHeadersFetcherContext))
+	var clientWatchdog *clientwatchdog.Watchdog
+	if cfg.ClientCheckPeriod > 0 {
+		if cfg.ClientRefreshThreshold < 0 || cfg.ClientRefreshThreshold >= 1 {
+			return nil, fmt.Errorf("client refresh threshold must be within [0, 1), got %v", cfg.ClientRefreshThreshold)
+		}
+		clientWatchdog = clientwatchdog.NewWatchdog(neutronChain, targetChain, trustedHeaderFetcher, txSender,
+			cfg.ClientRefreshThreshold, logRegistry.Get(ClientWatchdogContext))
+	}
+	var upgradeWatcher *chainupgrade.Watcher
+	if cfg.TargetChain.UpgradeCheckPeriod > 0 {
+		upgradeWatcher = chainupgrade.NewWatcher(
+			targetClient,
+			connParams.targetChainID,
+			trustedHeaderFetcher,
+			func(chainID string) (*cosmosrelayer.Chain, error) {
+				return loadTargetChain(cfg, logRegistry, connParams, chainID)
+			},
+			logRegistry.Get(UpgradeWatcherContext),
+		)
+	}
+	// a nil proofVerifier disables local proof verification
+	var proofVerifier relay.ProofVerifier
+	if cfg.VerifyProofs {
+		proofVerifier = proofverifier.NewProofVerifier()
+	}
 	txProcessor := txprocessor.NewTxProcessor(
-		trustedHeaderFetcher, storage, proofSubmitter, logRegistry.Get(TxProcessorContext), cfg.CheckSubmittedTxStatusDelay, cfg.IgnoreErrorsRegex)
+		trustedHeaderFetcher, storage, proofSubmitter, logRegistry.Get(TxProcessorContext), errorClassifier, proofVerifier)
 	kvProcessor := kvprocessor.NewKVProcessor(
 		trustedHeaderFetcher,
 		targetQuerier,
@@ -88,8 +242,9 @@ func NewDefaultDependencyContainer(ctx context.Context,
 		logRegistry.Get(KVProcessorContext),
 		proofSubmitter,
 		storage,
-		targetChain,
 		neutronChain,
+		proofVerifier,
+		errorClassifier,
 	)
 	return &DependencyContainer{
 		txQuerier:            txQuerier,
@@ -100,6 +255,16 @@ func NewDefaultDependencyContainer(ctx context.Context,
 		targetChain:          targetChain,
 		neutronChain:         neutronChain,
 		targetQuerier:        targetQuerier,
+		proofVerifier:        proofVerifier,
+		grantsChecker:        grantsChecker,
+		gasObserver:          txSender.GasObserver(),
+		errorClassifier:      errorClassifier,
+		senderAddr:           senderAddr,
+		janitor:              depositsJanitor,
+		neutronQuerier:       neutronQuerier,
+		upgradeWatcher:       upgradeWatcher,
+		clientWatchdog:       clientWatchdog,
+		chainMonitor:         chainMonitor,
 	}, nil
 }
 
@@ -134,3 +299,59 @@ func (c DependencyContainer) GetTrustedHeaderFetcher() relay.TrustedHeaderFetche
 func (c DependencyContainer) GetTargetQuerier() *tmquerier.Querier {
 	return c.targetQuerier
 }
+
+func (c DependencyContainer) GetProofVerifier() relay.ProofVerifier {
+	return c.proofVerifier
+}
+
+// GetGrantsChecker returns nil if neither fee grants nor authz are used
+func (c DependencyContainer) GetGrantsChecker() *grants.Checker {
+	return c.grantsChecker
+}
+
+// GetGasObserver returns nil if the gas estimation is disabled
+func (c DependencyContainer) GetGasObserver() relay.GasObserver {
+	return c.gasObserver
+}
+
+func (c DependencyContainer) GetErrorClassifier() relay.ErrorClassifier {
+	return c.errorClassifier
+}
+
+// GetSenderAddr returns the address the relayer signs the neutron txs with
+func (c DependencyContainer) GetSenderAddr() string {
+	return c.senderAddr
+}
+
+// GetJanitor returns nil if the deposits harvesting is disabled
+func (c DependencyContainer) GetJanitor() *janitor.Janitor {
+	return c.janitor
+}
+
+// GetNeutronQuerier returns the querier of the neutron registered queries and connections
+func (c DependencyContainer) GetNeutronQuerier() relay.NeutronQuerier {
+	return c.neutronQuerier
+}
+
+// GetUpgradeWatcher returns nil if the target chain upgrades are not watched
+func (c DependencyContainer) GetUpgradeWatcher() *chainupgrade.Watcher {
+	return c.upgradeWatcher
+}
+
+// GetClientWatchdog returns nil if the light client is not watched
+func (c DependencyContainer) GetClientWatchdog() *clientwatchdog.Watchdog {
+	return c.clientWatchdog
+}
+
+// GetChainMonitor returns nil if the target chain is not monitored
+func (c DependencyContainer) GetChainMonitor() *chainmonitor.Monitor {
+	return c.chainMonitor
+}
+
+// GetChainHealth returns nil if the target chain is not monitored
+func (c DependencyContainer) GetChainHealth() relay.ChainHealthProvider {
+	if c.chainMonitor == nil {
+		return nil
+	}
+	return c.chainMonitor
+}
@@ -0,0 +1,168 @@
+package chainmonitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// maxStatusFailures is the number of consecutive failures to get the target node status after which the target
+// chain is considered unhealthy, so a single failed request doesn't pause the processing
+const maxStatusFailures = 3
+
+// Monitor detects the target chain halts and the target node lags. The target chain is considered unhealthy
+// if the latest block of the target node is older than maxBlockAge, or if the node is catching up, or if it's
+// more than maxHeightLag blocks behind any of the other target nodes. The relayer pauses the query processing
+// while the target chain is unhealthy, since it would otherwise submit stale results.
+type Monitor struct {
+	client       rpcclient.Client
+	peers        []rpcclient.Client
+	maxBlockAge  time.Duration
+	maxHeightLag uint64
+	logger       *zap.Logger
+	// statusFailures is the number of consecutive failures to get the target node status, it's accessed by Check only
+	statusFailures int
+
+	mu     sync.RWMutex
+	health relay.TargetChainHealth
+}
+
+// NewMonitor constructs a new Monitor of the target node client. peers are the other target nodes to compare
+// the latest height with, they are optional.
+func NewMonitor(
+	client rpcclient.Client,
+	peers []rpcclient.Client,
+	maxBlockAge time.Duration,
+	maxHeightLag uint64,
+	logger *zap.Logger,
+) *Monitor {
+	neutronmetrics.SetTargetChainHealthy(true)
+	return &Monitor{
+		client:       client,
+		peers:        peers,
+		maxBlockAge:  maxBlockAge,
+		maxHeightLag: maxHeightLag,
+		logger:       logger,
+		// the target chain is assumed healthy until the first check
+		health: relay.TargetChainHealth{Healthy: true},
+	}
+}
+
+// TargetChainHealth implements relay.ChainHealthProvider
+func (m *Monitor) TargetChainHealth() relay.TargetChainHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.health
+}
+
+// Run checks the target chain periodically until the ctx is cancelled
+func (m *Monitor) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			m.logger.Info("context cancelled, shutting down chain monitor...")
+			return nil
+		}
+	}
+}
+
+// Check updates the target chain health and logs its transitions. The health is kept as is until the target
+// node status fails to be fetched maxStatusFailures times in a row. Check must not be called concurrently.
+func (m *Monitor) Check(ctx context.Context) {
+	health, err := m.check(ctx)
+	if err != nil {
+		m.statusFailures++
+		if m.statusFailures < maxStatusFailures {
+			m.logger.Warn("failed to get target node status", zap.Int("failures", m.statusFailures), zap.Error(err))
+			return
+		}
+		health.Reason = fmt.Sprintf("failed to get target node status %d times in a row: %s", m.statusFailures, err)
+	} else {
+		m.statusFailures = 0
+	}
+
+	m.mu.Lock()
+	previous := m.health
+	m.health = health
+	m.mu.Unlock()
+
+	neutronmetrics.SetTargetChainHealthy(health.Healthy)
+	switch {
+	case previous.Healthy && !health.Healthy:
+		m.logger.Warn("target chain is unhealthy, pausing queries processing",
+			zap.String("reason", health.Reason),
+			zap.Uint64("latest_height", health.LatestHeight),
+			zap.Time("latest_block_time", health.LatestBlockTime),
+			zap.Uint64("peers_height", health.PeersHeight))
+	case !previous.Healthy && health.Healthy:
+		m.logger.Info("target chain is healthy again, resuming queries processing",
+			zap.Uint64("latest_height", health.LatestHeight),
+			zap.Time("latest_block_time", health.LatestBlockTime))
+	}
+}
+
+// check returns the target chain health, or an error with an unhealthy one if the target node status can't be fetched
+func (m *Monitor) check(ctx context.Context) (relay.TargetChainHealth, error) {
+	health := relay.TargetChainHealth{CheckedAt: time.Now()}
+
+	status, err := m.client.Status(ctx)
+	if err != nil {
+		return health, err
+	}
+	health.LatestHeight = uint64(status.SyncInfo.LatestBlockHeight)
+	health.LatestBlockTime = status.SyncInfo.LatestBlockTime
+	health.PeersHeight = m.peersHeight(ctx)
+
+	blockAge := time.Since(health.LatestBlockTime)
+	neutronmetrics.SetTargetChainBlockAge(blockAge)
+	var heightLag uint64
+	if health.PeersHeight > health.LatestHeight {
+		heightLag = health.PeersHeight - health.LatestHeight
+	}
+	neutronmetrics.SetTargetChainHeightLag(heightLag)
+
+	switch {
+	case status.SyncInfo.CatchingUp:
+		health.Reason = "target node is catching up"
+	case heightLag > m.maxHeightLag:
+		health.Reason = fmt.Sprintf("target node is %d blocks behind the other nodes", heightLag)
+	case blockAge > m.maxBlockAge:
+		// the other nodes are not ahead, so the whole chain is stuck
+		health.Reason = fmt.Sprintf("target chain has not produced blocks for %s", blockAge.Round(time.Second))
+	default:
+		health.Healthy = true
+	}
+
+	return health, nil
+}
+
+// peersHeight returns the highest latest height of the other target nodes, the unreachable nodes are skipped
+func (m *Monitor) peersHeight(ctx context.Context) uint64 {
+	var height uint64
+	for _, peer := range m.peers {
+		status, err := peer.Status(ctx)
+		if err != nil {
+			m.logger.Debug("failed to get target peer status", zap.Error(err))
+			continue
+		}
+		if peerHeight := uint64(status.SyncInfo.LatestBlockHeight); peerHeight > height {
+			height = peerHeight
+		}
+	}
+
+	return height
+}
@@ -0,0 +1,132 @@
+package chainupgrade
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// ChainBuilder builds the target chain with the chain ID, i.e. the providers able to fetch its headers
+type ChainBuilder func(chainID string) (*relayer.Chain, error)
+
+// Watcher watches the target chain for upgrades changing its chain ID (and thereby its revision number).
+// On a change it builds the target chain for the new chain ID and hands it over to the tracker along with
+// the first height of the new chain ID, so the headers of both revisions can be fetched without a restart.
+type Watcher struct {
+	rpcClient rpcclient.Client
+	tracker   relay.TargetRevisionTracker
+	newChain  ChainBuilder
+	logger    *zap.Logger
+
+	chainID string
+	// height is the latest height the chainID has been observed at
+	height uint64
+}
+
+// NewWatcher constructs a new Watcher of the target chain currently having the chainID
+func NewWatcher(
+	rpcClient rpcclient.Client,
+	chainID string,
+	tracker relay.TargetRevisionTracker,
+	newChain ChainBuilder,
+	logger *zap.Logger,
+) *Watcher {
+	neutronmetrics.SetTargetChainRevision(clienttypes.ParseChainID(chainID))
+	return &Watcher{
+		rpcClient: rpcClient,
+		tracker:   tracker,
+		newChain:  newChain,
+		logger:    logger,
+		chainID:   chainID,
+	}
+}
+
+// Run checks the target chain ID every period until the ctx is cancelled
+func (w *Watcher) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Check(ctx); err != nil {
+				w.logger.Error("target chain upgrade check failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			w.logger.Info("context cancelled, shutting down upgrade watcher...")
+			return nil
+		}
+	}
+}
+
+// Check compares the target chain ID reported by the node status with the known one and switches over to
+// the new chain ID if they differ
+func (w *Watcher) Check(ctx context.Context) error {
+	status, err := w.rpcClient.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get target chain status: %w", err)
+	}
+	chainID, height := status.NodeInfo.Network, uint64(status.SyncInfo.LatestBlockHeight)
+	if chainID == w.chainID {
+		w.height = height
+		return nil
+	}
+
+	chain, err := w.newChain(chainID)
+	if err != nil {
+		return fmt.Errorf("failed to build target chain %s: %w", chainID, err)
+	}
+
+	// the heights are restarted if the new chain ID is already behind the old one, the new chain ID
+	// serves all the heights then
+	var startHeight uint64
+	if height > w.height {
+		startHeight = w.findStartHeight(ctx, chainID, w.height+1, height)
+	}
+	w.tracker.AddRevision(chain, startHeight)
+
+	w.logger.Warn("target chain ID has changed",
+		zap.String("old_chain_id", w.chainID),
+		zap.String("new_chain_id", chainID),
+		zap.Uint64("old_revision", clienttypes.ParseChainID(w.chainID)),
+		zap.Uint64("new_revision", clienttypes.ParseChainID(chainID)),
+		zap.Uint64("start_height", startHeight))
+	neutronmetrics.SetTargetChainRevision(clienttypes.ParseChainID(chainID))
+
+	w.chainID, w.height = chainID, height
+	return nil
+}
+
+// findStartHeight searches for the first block of the chainID within the [from, to] heights. If some block
+// can't be fetched (e.g. it's pruned), the search gives up and returns the to height, so the heights
+// in between are served by the old chain ID.
+func (w *Watcher) findStartHeight(ctx context.Context, chainID string, from uint64, to uint64) uint64 {
+	var searchErr error
+	offset := sort.Search(int(to-from), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		height := int64(from) + int64(i)
+		commit, err := w.rpcClient.Commit(ctx, &height)
+		if err != nil {
+			searchErr = fmt.Errorf("failed to get commit at height %d: %w", height, err)
+			return true
+		}
+		return commit.SignedHeader.Header.ChainID == chainID
+	})
+	if searchErr != nil {
+		w.logger.Error("failed to find the first height of the new target chain ID", zap.Error(searchErr))
+		return to
+	}
+
+	return from + uint64(offset)
+}
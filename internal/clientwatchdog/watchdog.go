@@ -0,0 +1,180 @@
+package clientwatchdog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// Watchdog follows the Neutron light client of the target chain. The client expires if it's not updated
+// within its trusting period, i.e. if no results are submitted for that long, and the proofs can't be
+// verified anymore until the client is recovered by the governance. To prevent it, the watchdog updates
+// the client on its own once the time left until the expiry falls below the refresh threshold.
+type Watchdog struct {
+	neutronChain  *relayer.Chain
+	targetChain   *relayer.Chain
+	headerFetcher relay.TrustedHeaderFetcher
+	txSender      relay.TxSender
+	// refreshThreshold is the fraction of the trusting period left at which the client is updated,
+	// 0 disables the updates
+	refreshThreshold float64
+	logger           *zap.Logger
+}
+
+func NewWatchdog(
+	neutronChain *relayer.Chain,
+	targetChain *relayer.Chain,
+	headerFetcher relay.TrustedHeaderFetcher,
+	txSender relay.TxSender,
+	refreshThreshold float64,
+	logger *zap.Logger,
+) *Watchdog {
+	return &Watchdog{
+		neutronChain:     neutronChain,
+		targetChain:      targetChain,
+		headerFetcher:    headerFetcher,
+		txSender:         txSender,
+		refreshThreshold: refreshThreshold,
+		logger:           logger,
+	}
+}
+
+// Run checks the client periodically until the ctx is cancelled
+func (w *Watchdog) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(ctx); err != nil {
+			w.logger.Error("light client check failed", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			w.logger.Info("context cancelled, shutting down client watchdog...")
+			return nil
+		}
+	}
+}
+
+// Check exports the client health and updates the client if it's about to expire. The frozen and the expired
+// clients can't be updated, they have to be recovered by the governance.
+func (w *Watchdog) Check(ctx context.Context) error {
+	clientID := w.neutronChain.PathEnd.ClientID
+	clientState, err := w.neutronChain.ChainProvider.QueryClientState(ctx, 0, clientID)
+	if err != nil {
+		return fmt.Errorf("could not fetch client state for ClientId=%s: %w", clientID, err)
+	}
+	tmClientState, ok := clientState.(*tmclient.ClientState)
+	if !ok {
+		return fmt.Errorf("expected client state of type *tmclient.ClientState, got %T", clientState)
+	}
+
+	frozen := !tmClientState.FrozenHeight.IsZero()
+	neutronmetrics.SetClientFrozen(frozen)
+	if frozen {
+		w.logger.Error("light client is frozen, it has to be recovered by the governance",
+			zap.String("client_id", clientID),
+			zap.String("frozen_height", tmClientState.FrozenHeight.String()))
+		return nil
+	}
+
+	consensusTime, err := w.latestConsensusTime(ctx, clientID, tmClientState.LatestHeight)
+	if err != nil {
+		return fmt.Errorf("failed to get latest consensus state time: %w", err)
+	}
+	remaining := time.Until(consensusTime.Add(tmClientState.TrustingPeriod))
+	neutronmetrics.SetClientTrustRemaining(remaining)
+	if remaining <= 0 {
+		w.logger.Error("light client has expired, it has to be recovered by the governance",
+			zap.String("client_id", clientID),
+			zap.Time("consensus_time", consensusTime))
+		return nil
+	}
+	w.logger.Debug("light client is healthy",
+		zap.String("client_id", clientID),
+		zap.Duration("trust_remaining", remaining))
+
+	if w.refreshThreshold <= 0 || remaining.Seconds() > tmClientState.TrustingPeriod.Seconds()*w.refreshThreshold {
+		return nil
+	}
+
+	if err := w.refresh(ctx, clientID); err != nil {
+		neutronmetrics.IncClientRefreshFailed()
+		return fmt.Errorf("failed to refresh client %s: %w", clientID, err)
+	}
+	neutronmetrics.IncClientRefreshSuccess()
+
+	return nil
+}
+
+// latestConsensusTime returns the timestamp of the client consensus state at its latest height
+func (w *Watchdog) latestConsensusTime(ctx context.Context, clientID string, latestHeight clienttypes.Height) (time.Time, error) {
+	// Without this hack it doesn't want to work with NewQueryClient
+	neutronProvider, ok := w.neutronChain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return time.Time{}, fmt.Errorf("failed to cast ChainProvider to concrete type (cosmos.CosmosProvider)")
+	}
+
+	res, err := clienttypes.NewQueryClient(neutronProvider).ConsensusState(ctx, &clienttypes.QueryConsensusStateRequest{
+		ClientId:       clientID,
+		RevisionNumber: latestHeight.RevisionNumber,
+		RevisionHeight: latestHeight.RevisionHeight,
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get consensus state at height %s: %w", latestHeight, err)
+	}
+
+	consensusState, ok := res.ConsensusState.GetCachedValue().(ibcexported.ConsensusState)
+	if !ok {
+		return time.Time{}, fmt.Errorf("couldn't cast consensus state value of type %T to ibcexported.ConsensusState", res.ConsensusState.GetCachedValue())
+	}
+
+	return time.Unix(0, int64(consensusState.GetTimestamp())), nil
+}
+
+// refresh submits a standalone client update with the latest target chain header
+func (w *Watchdog) refresh(ctx context.Context, clientID string) error {
+	latestHeight, err := w.targetChain.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get target chain latest height: %w", err)
+	}
+
+	header, err := w.headerFetcher.Fetch(ctx, uint64(latestHeight))
+	if err != nil {
+		return fmt.Errorf("failed to fetch header at height %d: %w", latestHeight, err)
+	}
+
+	updateMsg, err := w.neutronChain.ChainProvider.MsgUpdateClient(clientID, header)
+	if err != nil {
+		return fmt.Errorf("failed to build MsgUpdateClient: %w", err)
+	}
+	cosmosMsg, ok := updateMsg.(cosmos.CosmosMessage)
+	if !ok {
+		return fmt.Errorf("failed to cast provider.RelayerMessage to cosmos.CosmosMessage")
+	}
+
+	neutronHash, err := w.txSender.Send(ctx, []sdk.Msg{cosmosMsg.Msg}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send client update tx: %w", err)
+	}
+
+	w.logger.Info("light client refreshed",
+		zap.String("client_id", clientID),
+		zap.Int64("height", latestHeight),
+		zap.String("neutron_hash", neutronHash))
+
+	return nil
+}
@@ -11,25 +11,50 @@ import (
 
 // NeutronQueryRelayerConfig describes configuration of the app
 type NeutronQueryRelayerConfig struct {
-	NeutronChain                *NeutronChainConfig      `split_words:"true"`
-	TargetChain                 *TargetChainConfig       `split_words:"true"`
-	Registry                    *registry.RegistryConfig `split_words:"true"`
-	AllowTxQueries              bool                     `required:"true" split_words:"true"`
-	AllowKVCallbacks            bool                     `required:"true" split_words:"true"`
-	MinKvUpdatePeriod           uint64                   `split_words:"true" default:"0"`
-	StoragePath                 string                   `required:"true" split_words:"true"`
-	CheckSubmittedTxStatusDelay time.Duration            `split_words:"true" default:"10s"`
-	QueriesTaskQueueCapacity    int                      `split_words:"true" default:"10000"`
-	InitialTxSearchOffset       uint64                   `split_words:"true" default:"0"`
-	ListenAddr                  string                   `split_words:"true" default:"127.0.0.1:9999"`
-	IgnoreErrorsRegex           string                   `split_words:"true" default:"(execute wasm contract failed|failed to build tx query string)"`
+	NeutronChain               *NeutronChainConfig      `split_words:"true"`
+	TargetChain                *TargetChainConfig       `split_words:"true"`
+	Registry                   *registry.RegistryConfig `split_words:"true"`
+	AllowTxQueries             bool                     `required:"true" split_words:"true"`
+	AllowKVCallbacks           bool                     `required:"true" split_words:"true"`
+	MinKvUpdatePeriod          uint64                   `split_words:"true" default:"0"`
+	KvProofCacheHeights        uint64                   `split_words:"true" default:"0"`
+	KvHeightAlignmentWindow    time.Duration            `split_words:"true" default:"5s"`
+	StoragePath                string                   `required:"true" split_words:"true"`
+	ConfirmationDeadlineBlocks uint64                   `split_words:"true" default:"100"`
+	ConfirmationPollPeriod     time.Duration            `split_words:"true" default:"30s"`
+	QueriesTaskQueueCapacity   int                      `split_words:"true" default:"10000"`
+	TaskRetryBaseDelay         uint64                   `split_words:"true" default:"1"`
+	ReconciliationPeriod       time.Duration            `split_words:"true" default:"5m"`
+	CompetitorLagBlocks        uint64                   `split_words:"true" default:"0"`
+	InitialTxSearchOffset      uint64                   `split_words:"true" default:"0"`
+	ListenAddr                 string                   `split_words:"true" default:"127.0.0.1:9999"`
+	IgnoreErrorsRegex          string                   `split_words:"true" default:"(execute wasm contract failed|failed to build tx query string)"`
+	RetryableErrorsRegex       string                   `split_words:"true"`
+	CriticalErrorsRegex        string                   `split_words:"true"`
+	ErrorClasses               map[string]string        `split_words:"true" default:"sdk/13:retryable,sdk/20:retryable,sdk/32:retryable"`
+	VerifyProofs               bool                     `split_words:"true" default:"false"`
+	FeeGranter                 string                   `split_words:"true"`
+	FeeGrantByOwner            bool                     `split_words:"true" default:"false"`
+	AuthzGranter               string                   `split_words:"true"`
+	GrantsCheckPeriod          time.Duration            `split_words:"true" default:"1m"`
+	HarvestDeposits            bool                     `split_words:"true" default:"false"`
+	HarvestPeriod              time.Duration            `split_words:"true" default:"10m"`
+	HarvestBatchSize           uint64                   `split_words:"true" default:"10"`
+	HarvestOwnerDenyList       []string                 `split_words:"true"`
+	LeaseBackend               string                   `split_words:"true"`
+	LeaseFile                  string                   `split_words:"true"`
+	LeaseTimeout               time.Duration            `split_words:"true" default:"30s"`
+	LeaseHolder                string                   `split_words:"true"`
+	ClientCheckPeriod          time.Duration            `split_words:"true" default:"10m"`
+	ClientRefreshThreshold     float64                  `split_words:"true" default:"0"`
+	ShutdownDrainTimeout       time.Duration            `split_words:"true" default:"10s"`
 }
 
 const EnvPrefix string = "RELAYER"
 
 type NeutronChainConfig struct {
 	RPCAddr        string        `required:"true" split_words:"true"`
-	RESTAddr       string        `required:"true" split_words:"true"`
+	RESTAddr       string        `split_words:"true"`
 	HomeDir        string        `required:"true" split_words:"true"`
 	SignKeyName    string        `required:"true" split_words:"true"`
 	Timeout        time.Duration `split_words:"true" default:"10s"`
@@ -41,13 +66,64 @@ type NeutronChainConfig struct {
 	KeyringBackend string        `required:"true" split_words:"true"`
 	OutputFormat   string        `split_words:"true" default:"json"`
 	SignModeStr    string        `split_words:"true" default:"direct"`
+	// KeyringPassphrase and KeyringPassphraseFile are used to unlock the `file` keyring backend
+	KeyringPassphrase     string `split_words:"true"`
+	KeyringPassphraseFile string `split_words:"true"`
+	// RemoteSignerAddr is the address of the remote signer to sign transactions with instead of the keyring,
+	// the RemoteSignerAuthToken is sent as a bearer token and the files configure the TLS of the https address
+	RemoteSignerAddr      string `split_words:"true"`
+	RemoteSignerAuthToken string `split_words:"true"`
+	RemoteSignerCAFile    string `split_words:"true"`
+	RemoteSignerCertFile  string `split_words:"true"`
+	RemoteSignerKeyFile   string `split_words:"true"`
+	// GasPriceSource is one of `static`, `recent_blocks` or `globalfee`, the GasPrices is the minimal price
+	// for the dynamic sources
+	GasPriceSource           string  `split_words:"true" default:"static"`
+	GasPriceRecentBlocks     uint64  `split_words:"true" default:"10"`
+	GasPricePercentile       uint64  `split_words:"true" default:"50"`
+	GasPriceCap              string  `split_words:"true"`
+	GasPriceEscalationFactor float64 `split_words:"true" default:"1.2"`
+	// GasEstimationWindow is the number of recent gas usages kept per gas profile to skip the simulation
+	// of repetitive transactions, 0 disables the estimation
+	GasEstimationWindow     uint64  `split_words:"true" default:"10"`
+	GasEstimationMinSamples uint64  `split_words:"true" default:"3"`
+	GasEstimationMaxSpread  float64 `split_words:"true" default:"0.1"`
+	// BroadcastMode is one of `sync`, `async` or `commit`
+	BroadcastMode string `split_words:"true" default:"sync"`
+	// TxTimeoutBlocks is the number of blocks a transaction can be included within, 0 disables the timeout
+	TxTimeoutBlocks uint64 `split_words:"true" default:"0"`
+}
+
+// RemoteSignerConfig describes configuration of the reference remote signer server
+type RemoteSignerConfig struct {
+	ListenAddr      string   `split_words:"true" default:"127.0.0.1:9998"`
+	ChainID         string   `required:"true" split_words:"true"`
+	AllowedMsgTypes []string `split_words:"true"`
+	// MaxFee is the fee cap of a transaction, the fee can be paid only in its denoms. Empty disables the check.
+	MaxFee string `split_words:"true"`
+	// AuthToken is the bearer token the clients have to present, empty disables the check
+	AuthToken string `split_words:"true"`
+	// TLSCertFile and TLSKeyFile enable TLS, TLSClientCAFile makes the server require the client certificates
+	TLSCertFile     string `split_words:"true"`
+	TLSKeyFile      string `split_words:"true"`
+	TLSClientCAFile string `split_words:"true"`
 }
 
 type TargetChainConfig struct {
-	RPCAddr      string        `required:"true" split_words:"true"`
-	Timeout      time.Duration `split_words:"true" default:"10s"`
-	Debug        bool          `split_words:"true" default:"false"`
-	OutputFormat string        `split_words:"true" default:"json"`
+	RPCAddr         string        `required:"true" split_words:"true"`
+	Timeout         time.Duration `split_words:"true" default:"10s"`
+	Debug           bool          `split_words:"true" default:"false"`
+	OutputFormat    string        `split_words:"true" default:"json"`
+	QuorumRPCAddrs  []string      `split_words:"true"`
+	QuorumThreshold int           `split_words:"true" default:"0"`
+	// UpgradeCheckPeriod is how often the target chain ID is checked for a change by an upgrade, 0 disables the check
+	UpgradeCheckPeriod time.Duration `split_words:"true" default:"1m"`
+	// HaltCheckPeriod is how often the target chain is checked for a halt or a lag of the target node, the queries
+	// processing is paused while the latest block is older than MaxBlockAge or the node is more than MaxHeightLag
+	// blocks behind the quorum nodes. 0 disables the check.
+	HaltCheckPeriod time.Duration `split_words:"true" default:"30s"`
+	MaxBlockAge     time.Duration `split_words:"true" default:"5m"`
+	MaxHeightLag    uint64        `split_words:"true" default:"10"`
 }
 
 func NewNeutronQueryRelayerConfig() (NeutronQueryRelayerConfig, error) {
@@ -60,3 +136,46 @@ func NewNeutronQueryRelayerConfig() (NeutronQueryRelayerConfig, error) {
 
 	return cfg, nil
 }
+
+// neutronChainKeysConfig is the part of the NeutronChainConfig needed to manage the relayer keys
+type neutronChainKeysConfig struct {
+	RPCAddr               string        `split_words:"true"`
+	HomeDir               string        `required:"true" split_words:"true"`
+	SignKeyName           string        `split_words:"true"`
+	Timeout               time.Duration `split_words:"true" default:"10s"`
+	KeyringBackend        string        `required:"true" split_words:"true"`
+	KeyringPassphrase     string        `split_words:"true"`
+	KeyringPassphraseFile string        `split_words:"true"`
+}
+
+// NewNeutronChainKeysConfig reads only the keyring related part of the NeutronChainConfig, so the keys
+// can be managed before the rest of the relayer is configured.
+func NewNeutronChainKeysConfig() (NeutronChainConfig, error) {
+	var cfg neutronChainKeysConfig
+
+	err := envconfig.Process(EnvPrefix+"_NEUTRON_CHAIN", &cfg)
+	if err != nil {
+		return NeutronChainConfig{}, fmt.Errorf("could not read neutron chain keys config from env: %w", err)
+	}
+
+	return NeutronChainConfig{
+		RPCAddr:               cfg.RPCAddr,
+		HomeDir:               cfg.HomeDir,
+		SignKeyName:           cfg.SignKeyName,
+		Timeout:               cfg.Timeout,
+		KeyringBackend:        cfg.KeyringBackend,
+		KeyringPassphrase:     cfg.KeyringPassphrase,
+		KeyringPassphraseFile: cfg.KeyringPassphraseFile,
+	}, nil
+}
+
+func NewRemoteSignerConfig() (RemoteSignerConfig, error) {
+	var cfg RemoteSignerConfig
+
+	err := envconfig.Process(EnvPrefix+"_REMOTE_SIGNER", &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("could not read remote signer config from env: %w", err)
+	}
+
+	return cfg, nil
+}
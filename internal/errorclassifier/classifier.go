@@ -0,0 +1,99 @@
+package errorclassifier
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+type codeKey struct {
+	codespace string
+	code      uint32
+}
+
+// Classifier classifies the errors by the codespace and code of the rejected transactions first. Errors
+// with unknown codes and errors not caused by a rejected transaction are matched against the regexps
+// in the order: critical, ignorable, retryable.
+type Classifier struct {
+	codeClasses   map[codeKey]relay.ErrorClass
+	regexpClasses []regexpClass
+}
+
+type regexpClass struct {
+	regexp *regexp.Regexp
+	class  relay.ErrorClass
+}
+
+// NewClassifier constructs a new Classifier. codeClasses maps `codespace/code` to the error class, the empty
+// regexps are not used.
+func NewClassifier(codeClasses map[string]string, criticalRegexp, ignorableRegexp, retryableRegexp string) (*Classifier, error) {
+	classifier := &Classifier{codeClasses: make(map[codeKey]relay.ErrorClass, len(codeClasses))}
+
+	for key, class := range codeClasses {
+		codespace, codeStr, ok := strings.Cut(key, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid error code %s, expected codespace/code", key)
+		}
+		code, err := strconv.ParseUint(codeStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error code %s: %w", key, err)
+		}
+		errorClass, err := parseErrorClass(class)
+		if err != nil {
+			return nil, fmt.Errorf("invalid class of error code %s: %w", key, err)
+		}
+		classifier.codeClasses[codeKey{codespace: codespace, code: uint32(code)}] = errorClass
+	}
+
+	for _, rc := range []struct {
+		expr  string
+		class relay.ErrorClass
+	}{
+		{criticalRegexp, relay.ErrorClassCritical},
+		{ignorableRegexp, relay.ErrorClassIgnorable},
+		{retryableRegexp, relay.ErrorClassRetryable},
+	} {
+		if rc.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(rc.expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s errors regexp %s: %w", rc.class, rc.expr, err)
+		}
+		classifier.regexpClasses = append(classifier.regexpClasses, regexpClass{regexp: re, class: rc.class})
+	}
+
+	return classifier, nil
+}
+
+// Classify returns the class of the err, or relay.ErrorClassUnknown if neither its code nor its message
+// is known
+func (c *Classifier) Classify(err error) relay.ErrorClass {
+	var txErr *relay.TxError
+	if errors.As(err, &txErr) {
+		if class, ok := c.codeClasses[codeKey{codespace: txErr.Codespace, code: txErr.Code}]; ok {
+			return class
+		}
+	}
+
+	for _, rc := range c.regexpClasses {
+		if rc.regexp.MatchString(err.Error()) {
+			return rc.class
+		}
+	}
+
+	return relay.ErrorClassUnknown
+}
+
+func parseErrorClass(class string) (relay.ErrorClass, error) {
+	switch errorClass := relay.ErrorClass(class); errorClass {
+	case relay.ErrorClassRetryable, relay.ErrorClassIgnorable, relay.ErrorClassCritical:
+		return errorClass, nil
+	default:
+		return relay.ErrorClassUnknown, fmt.Errorf("unknown error class %s", class)
+	}
+}
@@ -0,0 +1,142 @@
+package errorclassifier
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+func TestClassify(t *testing.T) {
+	classifier, err := NewClassifier(
+		map[string]string{"sdk/13": "retryable", "wasm/5": "ignorable", "interchainqueries/1101": "critical"},
+		"critical failure",
+		"ignorable failure|critical failure",
+		"retryable failure|ignorable failure|critical failure",
+	)
+	if err != nil {
+		t.Fatalf("failed to create classifier: %s", err)
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		expectClass relay.ErrorClass
+	}{
+		{
+			name:        "wrapped tx error code",
+			err:         fmt.Errorf("error broadcasting sync transaction: %w", &relay.TxError{Codespace: "sdk", Code: 13, Log: "insufficient fee"}),
+			expectClass: relay.ErrorClassRetryable,
+		},
+		{
+			name:        "twice wrapped tx error code",
+			err:         fmt.Errorf("could not submit proof: %w", fmt.Errorf("error calculating gas: %w", &relay.TxError{Codespace: "wasm", Code: 5})),
+			expectClass: relay.ErrorClassIgnorable,
+		},
+		{
+			name:        "code takes precedence over regexps",
+			err:         fmt.Errorf("submit: %w", &relay.TxError{Codespace: "interchainqueries", Code: 1101, Log: "retryable failure"}),
+			expectClass: relay.ErrorClassCritical,
+		},
+		{
+			name:        "same code in another codespace",
+			err:         &relay.TxError{Codespace: "wasm", Code: 13, Log: "unknown"},
+			expectClass: relay.ErrorClassUnknown,
+		},
+		{
+			name:        "unknown code falls back to regexps",
+			err:         fmt.Errorf("submit: %w", &relay.TxError{Codespace: "sdk", Code: 99, Log: "retryable failure"}),
+			expectClass: relay.ErrorClassRetryable,
+		},
+		{
+			name:        "critical regexp first",
+			err:         errors.New("critical failure"),
+			expectClass: relay.ErrorClassCritical,
+		},
+		{
+			name:        "ignorable regexp before retryable",
+			err:         errors.New("ignorable failure"),
+			expectClass: relay.ErrorClassIgnorable,
+		},
+		{
+			name:        "retryable regexp",
+			err:         errors.New("retryable failure"),
+			expectClass: relay.ErrorClassRetryable,
+		},
+		{
+			name:        "unknown error",
+			err:         errors.New("connection refused"),
+			expectClass: relay.ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if class := classifier.Classify(tt.err); class != tt.expectClass {
+				t.Fatalf("expected class %q, got %q", tt.expectClass, class)
+			}
+		})
+	}
+}
+
+func TestNewClassifierInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		codes      map[string]string
+		ignoreExpr string
+	}{
+		{name: "no codespace", codes: map[string]string{"13": "retryable"}},
+		{name: "non numeric code", codes: map[string]string{"sdk/abc": "retryable"}},
+		{name: "negative code", codes: map[string]string{"sdk/-1": "retryable"}},
+		{name: "code overflow", codes: map[string]string{"sdk/4294967296": "retryable"}},
+		{name: "unknown class", codes: map[string]string{"sdk/13": "fatal"}},
+		{name: "empty class", codes: map[string]string{"sdk/13": ""}},
+		{name: "invalid regexp", ignoreExpr: "("},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClassifier(tt.codes, "", tt.ignoreExpr, ""); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestDefaultIgnoreErrorsRegex(t *testing.T) {
+	cfgType := reflect.TypeOf(config.NeutronQueryRelayerConfig{})
+	ignoreField, ok := cfgType.FieldByName("IgnoreErrorsRegex")
+	if !ok {
+		t.Fatal("IgnoreErrorsRegex config field not found")
+	}
+
+	classesField, ok := cfgType.FieldByName("ErrorClasses")
+	if !ok {
+		t.Fatal("ErrorClasses config field not found")
+	}
+	// the map default is formatted the envconfig way: key:value,key:value
+	codeClasses := make(map[string]string)
+	for _, pair := range strings.Split(classesField.Tag.Get("default"), ",") {
+		code, class, _ := strings.Cut(pair, ":")
+		codeClasses[code] = class
+	}
+
+	classifier, err := NewClassifier(codeClasses, "", ignoreField.Tag.Get("default"), "")
+	if err != nil {
+		t.Fatalf("failed to create classifier: %s", err)
+	}
+
+	// the baseline submission error of a failed sudo call, the wasm code is not in the default code classes
+	err = fmt.Errorf("could not submit proof: %w", &relay.TxError{
+		Codespace: "wasm",
+		Code:      5,
+		Log:       "failed to execute message; message index: 0: Generic error: execute wasm contract failed",
+	})
+	if class := classifier.Classify(err); class != relay.ErrorClassIgnorable {
+		t.Fatalf("expected class %q, got %q", relay.ErrorClassIgnorable, class)
+	}
+}
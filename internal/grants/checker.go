@@ -0,0 +1,323 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/raw"
+)
+
+const (
+	feeAllowanceQueryPath    = "/cosmos.feegrant.v1beta1.Query/Allowance"
+	authzGrantsQueryPath     = "/cosmos.authz.v1beta1.Query/Grants"
+	registeredQueryQueryPath = "/neutron.interchainqueries.Query/RegisteredQuery"
+
+	grantTypeFee   = "fee"
+	grantTypeAuthz = "authz"
+)
+
+// Checker checks the fee and authz grants given to the relayer and decides who pays for the query
+// results submission. The fee grant of a query owner is used for its queries (if enabled and the owner
+// has granted an allowance to the relayer), otherwise the global fee granter pays (if set), otherwise
+// the relayer pays itself.
+type Checker struct {
+	rpcClient       rpcclient.Client
+	unpacker        types.AnyUnpacker
+	grantee         string
+	feeGranter      string
+	feeGrantByOwner bool
+	authzGranter    string
+	logger          *zap.Logger
+
+	lock sync.Mutex
+	// ownerGrants contains whether the fee grant is available for every query owner checked so far
+	ownerGrants map[string]bool
+	// queryOwners caches owners of the queries
+	queryOwners map[uint64]string
+}
+
+// NewChecker constructs a new Checker of the grants given to the grantee. owners are the query owners
+// known in advance (e.g. the registry addresses) to check their fee grants at startup.
+func NewChecker(
+	rpcClient rpcclient.Client,
+	unpacker types.AnyUnpacker,
+	grantee string,
+	feeGranter string,
+	feeGrantByOwner bool,
+	authzGranter string,
+	owners []string,
+	logger *zap.Logger,
+) *Checker {
+	ownerGrants := make(map[string]bool, len(owners))
+	if feeGrantByOwner {
+		for _, owner := range owners {
+			ownerGrants[owner] = false
+		}
+	}
+
+	return &Checker{
+		rpcClient:       rpcClient,
+		unpacker:        unpacker,
+		grantee:         grantee,
+		feeGranter:      feeGranter,
+		feeGrantByOwner: feeGrantByOwner,
+		authzGranter:    authzGranter,
+		logger:          logger,
+		ownerGrants:     ownerGrants,
+		queryOwners:     make(map[uint64]string),
+	}
+}
+
+// Check checks all the grants and updates their metrics. It fails if the global fee grant or the authz
+// grant is not available, a missing fee grant of a query owner only means that the relayer pays for the
+// owner's queries.
+func (c *Checker) Check(ctx context.Context) error {
+	if c.feeGranter != "" {
+		available, err := c.checkFeeGrant(ctx, c.feeGranter)
+		if err != nil {
+			return fmt.Errorf("failed to check fee grant from %s: %w", c.feeGranter, err)
+		}
+		if !available {
+			return fmt.Errorf("fee grant from %s to %s is not found or expired", c.feeGranter, c.grantee)
+		}
+	}
+
+	if c.authzGranter != "" {
+		available, err := c.checkAuthzGrant(ctx, c.authzGranter)
+		if err != nil {
+			return fmt.Errorf("failed to check authz grant from %s: %w", c.authzGranter, err)
+		}
+		if !available {
+			return fmt.Errorf("authz grant to execute %s from %s to %s is not found or expired",
+				sdk.MsgTypeURL(&neutrontypes.MsgSubmitQueryResult{}), c.authzGranter, c.grantee)
+		}
+	}
+
+	for _, owner := range c.knownOwners() {
+		available, err := c.checkFeeGrant(ctx, owner)
+		if err != nil {
+			return fmt.Errorf("failed to check fee grant from query owner %s: %w", owner, err)
+		}
+		c.setOwnerGrant(owner, available)
+		if !available {
+			c.logger.Warn("query owner has no fee grant for the relayer, the relayer pays for its queries",
+				zap.String("owner", owner))
+		}
+	}
+
+	return nil
+}
+
+// Run periodically re-checks the grants to keep the metrics and the query owners fee grants up to date.
+// Check errors are logged only.
+func (c *Checker) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Check(ctx); err != nil {
+				c.logger.Error("grants check failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			c.logger.Info("context cancelled, shutting down grants checker...")
+			return nil
+		}
+	}
+}
+
+// FeeGranter returns the address of the fee granter paying for the query result submission,
+// or an empty string if the relayer pays itself.
+func (c *Checker) FeeGranter(ctx context.Context, queryID uint64) (string, error) {
+	if c.feeGrantByOwner {
+		owner, err := c.queryOwner(ctx, queryID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get owner of query %d: %w", queryID, err)
+		}
+
+		available, err := c.ownerGrant(ctx, owner)
+		if err != nil {
+			return "", fmt.Errorf("failed to check fee grant from query owner %s: %w", owner, err)
+		}
+		if available {
+			return owner, nil
+		}
+	}
+
+	return c.feeGranter, nil
+}
+
+// AuthzGranter returns the address MsgSubmitQueryResult is executed on behalf of with MsgExec,
+// or an empty string if the relayer submits results itself.
+func (c *Checker) AuthzGranter() string {
+	return c.authzGranter
+}
+
+func (c *Checker) knownOwners() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	owners := make([]string, 0, len(c.ownerGrants))
+	for owner := range c.ownerGrants {
+		owners = append(owners, owner)
+	}
+
+	return owners
+}
+
+func (c *Checker) setOwnerGrant(owner string, available bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ownerGrants[owner] = available
+}
+
+// ownerGrant returns whether the owner's fee grant is available. The grant of a new owner is checked
+// right away, known owners' grants are re-checked by Run.
+func (c *Checker) ownerGrant(ctx context.Context, owner string) (bool, error) {
+	c.lock.Lock()
+	available, ok := c.ownerGrants[owner]
+	c.lock.Unlock()
+	if ok {
+		return available, nil
+	}
+
+	available, err := c.checkFeeGrant(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+	c.setOwnerGrant(owner, available)
+
+	return available, nil
+}
+
+func (c *Checker) queryOwner(ctx context.Context, queryID uint64) (string, error) {
+	c.lock.Lock()
+	owner, ok := c.queryOwners[queryID]
+	c.lock.Unlock()
+	if ok {
+		return owner, nil
+	}
+
+	request := neutrontypes.QueryRegisteredQueryRequest{QueryId: queryID}
+	var response neutrontypes.QueryRegisteredQueryResponse
+	if err := raw.ABCIQuery(ctx, c.rpcClient, registeredQueryQueryPath, &request, &response); err != nil {
+		return "", err
+	}
+	if response.RegisteredQuery == nil {
+		return "", fmt.Errorf("query %d not found", queryID)
+	}
+
+	c.lock.Lock()
+	c.queryOwners[queryID] = response.RegisteredQuery.Owner
+	c.lock.Unlock()
+
+	return response.RegisteredQuery.Owner, nil
+}
+
+// checkFeeGrant returns whether the fee grant from the granter is available and exports its remaining
+// allowance as metrics
+func (c *Checker) checkFeeGrant(ctx context.Context, granter string) (bool, error) {
+	request := feegrant.QueryAllowanceRequest{Granter: granter, Grantee: c.grantee}
+	var response feegrant.QueryAllowanceResponse
+	if err := raw.ABCIQuery(ctx, c.rpcClient, feeAllowanceQueryPath, &request, &response); err != nil {
+		var abciErr raw.ABCIError
+		if errors.As(err, &abciErr) {
+			// the query fails if there is no allowance
+			c.logger.Debug("failed to query fee allowance", zap.String("granter", granter), zap.Error(err))
+			neutronmetrics.SetGrantAvailable(granter, grantTypeFee, false)
+			return false, nil
+		}
+		return false, err
+	}
+	if response.Allowance == nil {
+		neutronmetrics.SetGrantAvailable(granter, grantTypeFee, false)
+		return false, nil
+	}
+
+	var allowance feegrant.FeeAllowanceI
+	if err := c.unpacker.UnpackAny(response.Allowance.Allowance, &allowance); err != nil {
+		return false, fmt.Errorf("failed to unpack fee allowance: %w", err)
+	}
+
+	spendLimit, expiration, err := remainingAllowance(allowance)
+	if err != nil {
+		return false, fmt.Errorf("failed to get remaining fee allowance: %w", err)
+	}
+
+	available := expiration == nil || time.Now().Before(*expiration)
+	neutronmetrics.SetGrantAvailable(granter, grantTypeFee, available)
+	for _, coin := range spendLimit {
+		amount, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float64()
+		neutronmetrics.SetFeeGrantAllowance(granter, coin.Denom, amount)
+	}
+
+	return available, nil
+}
+
+// checkAuthzGrant returns whether the grant to execute MsgSubmitQueryResult on behalf of the granter is available
+func (c *Checker) checkAuthzGrant(ctx context.Context, granter string) (bool, error) {
+	request := authz.QueryGrantsRequest{
+		Granter:    granter,
+		Grantee:    c.grantee,
+		MsgTypeUrl: sdk.MsgTypeURL(&neutrontypes.MsgSubmitQueryResult{}),
+	}
+	var response authz.QueryGrantsResponse
+	if err := raw.ABCIQuery(ctx, c.rpcClient, authzGrantsQueryPath, &request, &response); err != nil {
+		var abciErr raw.ABCIError
+		if errors.As(err, &abciErr) {
+			c.logger.Debug("failed to query authz grants", zap.String("granter", granter), zap.Error(err))
+			neutronmetrics.SetGrantAvailable(granter, grantTypeAuthz, false)
+			return false, nil
+		}
+		return false, err
+	}
+
+	available := false
+	for _, grant := range response.Grants {
+		if time.Now().Before(grant.Expiration) {
+			available = true
+			break
+		}
+	}
+	neutronmetrics.SetGrantAvailable(granter, grantTypeAuthz, available)
+
+	return available, nil
+}
+
+// remainingAllowance returns the remaining spend limit (empty if unlimited) and the expiration (nil if
+// never expires) of the fee allowance
+func remainingAllowance(allowance feegrant.FeeAllowanceI) (sdk.Coins, *time.Time, error) {
+	switch a := allowance.(type) {
+	case *feegrant.BasicAllowance:
+		return a.SpendLimit, a.Expiration, nil
+	case *feegrant.PeriodicAllowance:
+		// the period is reset lazily on the next use of the allowance
+		if !time.Now().Before(a.PeriodReset) {
+			return a.PeriodSpendLimit, a.Basic.Expiration, nil
+		}
+		return a.PeriodCanSpend, a.Basic.Expiration, nil
+	case *feegrant.AllowedMsgAllowance:
+		inner, err := a.GetAllowance()
+		if err != nil {
+			return nil, nil, err
+		}
+		return remainingAllowance(inner)
+	default:
+		return nil, nil, fmt.Errorf("unsupported fee allowance type %T", allowance)
+	}
+}
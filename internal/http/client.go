@@ -65,6 +65,93 @@ func (c ICQClient) GetUnsuccessfulTxs() ([]relay.UnsuccessfulTxInfo, error) {
 	return txs, nil
 }
 
+func (c ICQClient) GetUnsuccessfulKVs() ([]relay.UnsuccessfulKVInfo, error) {
+	u := *c.host
+	u.Path = UnsuccessfulKVsResource
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build http request: %w", err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("got unexpected http response status code: %d", res.StatusCode)
+	}
+	kvs := make([]relay.UnsuccessfulKVInfo, 0)
+
+	decoder := json.NewDecoder(res.Body)
+	err = decoder.Decode(&kvs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return kvs, nil
+}
+
+func (c ICQClient) GetQuorumIncidents() ([]relay.QuorumIncident, error) {
+	u := *c.host
+	u.Path = QuorumIncidentsResource
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build http request: %w", err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("got unexpected http response status code: %d", res.StatusCode)
+	}
+	incidents := make([]relay.QuorumIncident, 0)
+
+	decoder := json.NewDecoder(res.Body)
+	err = decoder.Decode(&incidents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return incidents, nil
+}
+
+func (c ICQClient) GetHarvestedDeposits() ([]relay.HarvestedDeposit, error) {
+	u := *c.host
+	u.Path = HarvestedDepositsResource
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build http request: %w", err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("got unexpected http response status code: %d", res.StatusCode)
+	}
+	deposits := make([]relay.HarvestedDeposit, 0)
+
+	decoder := json.NewDecoder(res.Body)
+	err = decoder.Decode(&deposits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return deposits, nil
+}
+
 func (c ICQClient) ResubmitTxs(txs ResubmitRequest) error {
 	u := *c.host
 	u.Path = ResubmitTxs
@@ -99,3 +186,38 @@ func (c ICQClient) ResubmitTxs(txs ResubmitRequest) error {
 
 	return nil
 }
+
+func (c ICQClient) ResubmitKVs(kvs ResubmitKVRequest) error {
+	u := *c.host
+	u.Path = ResubmitKVs
+	body := bytes.Buffer{}
+	encoder := json.NewEncoder(&body)
+	err := encoder.Encode(kvs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kvs: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to build http request: %w", err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 400 {
+		errBody := bytes.Buffer{}
+		_, err = errBody.ReadFrom(res.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response(code 400) body: %w", err)
+		}
+		return fmt.Errorf(errBody.String())
+	} else if res.StatusCode != 200 {
+		return fmt.Errorf("got unexpected http response status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -37,7 +37,16 @@ func (p PromWrapper) fillUnsuccessfulTxsMetric() {
 	metrics.SetUnsuccessfulTxsSizeQueue(len(txs))
 }
 
+func (p PromWrapper) fillUnsuccessfulKVsMetric() {
+	kvs, err := p.storage.GetAllUnsuccessfulKVs()
+	if err != nil {
+		p.logger.Error("failed to get unsuccessful kvs from storage", zap.Error(err))
+	}
+	metrics.SetUnsuccessfulKVsSizeQueue(len(kvs))
+}
+
 func (p PromWrapper) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	p.fillUnsuccessfulTxsMetric()
+	p.fillUnsuccessfulKVsMetric()
 	p.promHandler.ServeHTTP(res, req)
 }
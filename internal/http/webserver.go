@@ -20,10 +20,15 @@ import (
 )
 
 const (
-	ServerContext           = "http"
-	UnsuccessfulTxsResource = "/unsuccessful-txs"
-	ResubmitTxs             = "/resubmit-txs"
-	PrometheusMetrics       = "/metrics"
+	ServerContext             = "http"
+	UnsuccessfulTxsResource   = "/unsuccessful-txs"
+	ResubmitTxs               = "/resubmit-txs"
+	QuorumIncidentsResource   = "/quorum-incidents"
+	UnsuccessfulKVsResource   = "/unsuccessful-kvs"
+	ResubmitKVs               = "/resubmit-kvs"
+	HealthResource            = "/health"
+	HarvestedDepositsResource = "/harvested-deposits"
+	PrometheusMetrics         = "/metrics"
 )
 
 type ResubmitTx struct {
@@ -35,10 +40,20 @@ type ResubmitRequest struct {
 	Txs []ResubmitTx `json:"txs"`
 }
 
-func Run(ctx context.Context, logRegistry *nlogger.Registry, storage relay.Storage, txProcessor relay.TXProcessor, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo, ListenAddr string) error {
+type ResubmitKVRequest struct {
+	QueryIDs []uint64 `json:"query_ids"`
+}
+
+type HealthResponse struct {
+	Role string `json:"role"`
+	// TargetChain is omitted if the target chain is not monitored
+	TargetChain *relay.TargetChainHealth `json:"target_chain,omitempty"`
+}
+
+func Run(ctx context.Context, logRegistry *nlogger.Registry, storage relay.Storage, txProcessor relay.TXProcessor, kvProcessor relay.KVProcessor, errorClassifier relay.ErrorClassifier, roleProvider relay.RoleProvider, chainHealth relay.ChainHealthProvider, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo, ListenAddr string) error {
 	server := &http.Server{
 		Addr:    ListenAddr,
-		Handler: Router(logRegistry, storage, txProcessor, submittedTxsTasksQueue),
+		Handler: Router(logRegistry, storage, txProcessor, kvProcessor, errorClassifier, roleProvider, chainHealth, submittedTxsTasksQueue),
 	}
 	logger := logRegistry.Get(ServerContext)
 	errch := make(chan error)
@@ -70,11 +85,16 @@ func Run(ctx context.Context, logRegistry *nlogger.Registry, storage relay.Stora
 	return nil
 }
 
-func Router(logRegistry *nlogger.Registry, storage relay.Storage, txProcessor relay.TXProcessor, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) *mux.Router {
+func Router(logRegistry *nlogger.Registry, storage relay.Storage, txProcessor relay.TXProcessor, kvProcessor relay.KVProcessor, errorClassifier relay.ErrorClassifier, roleProvider relay.RoleProvider, chainHealth relay.ChainHealthProvider, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) *mux.Router {
 	promHandler := NewPromWrapper(logRegistry, storage)
 	router := mux.NewRouter().StrictSlash(true)
 	router.HandleFunc(UnsuccessfulTxsResource, unsuccessfulTxs(logRegistry.Get(ServerContext), storage))
-	router.HandleFunc(ResubmitTxs, resubmitFailedTxs(logRegistry.Get(ServerContext), storage, txProcessor, submittedTxsTasksQueue)).Methods(http.MethodPost)
+	router.HandleFunc(ResubmitTxs, resubmitFailedTxs(logRegistry.Get(ServerContext), storage, txProcessor, errorClassifier, roleProvider, submittedTxsTasksQueue)).Methods(http.MethodPost)
+	router.HandleFunc(UnsuccessfulKVsResource, unsuccessfulKVs(logRegistry.Get(ServerContext), storage))
+	router.HandleFunc(ResubmitKVs, resubmitFailedKVs(logRegistry.Get(ServerContext), storage, kvProcessor, errorClassifier, roleProvider, submittedTxsTasksQueue)).Methods(http.MethodPost)
+	router.HandleFunc(QuorumIncidentsResource, quorumIncidents(logRegistry.Get(ServerContext), storage))
+	router.HandleFunc(HarvestedDepositsResource, harvestedDeposits(logRegistry.Get(ServerContext), storage))
+	router.HandleFunc(HealthResource, health(logRegistry.Get(ServerContext), roleProvider, chainHealth))
 	router.Handle(PrometheusMetrics, promHandler)
 	return router
 }
@@ -98,8 +118,70 @@ func unsuccessfulTxs(logger *zap.Logger, storage relay.Storage) http.HandlerFunc
 	}
 }
 
-func resubmitFailedTxs(logger *zap.Logger, store relay.Storage, txProcessor relay.TXProcessor, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) http.HandlerFunc {
+func quorumIncidents(logger *zap.Logger, storage relay.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := storage.GetAllQuorumIncidents()
+		if err != nil {
+			logger.Error("failed to execute GetAllQuorumIncidents", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(res)
+		if err != nil {
+			logger.Error("failed to encode result of GetAllQuorumIncidents", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+		}
+	}
+}
+
+func harvestedDeposits(logger *zap.Logger, storage relay.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := storage.GetAllHarvestedDeposits()
+		if err != nil {
+			logger.Error("failed to execute GetAllHarvestedDeposits", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(res)
+		if err != nil {
+			logger.Error("failed to encode result of GetAllHarvestedDeposits", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+		}
+	}
+}
+
+// health responds with the role of the relayer instance and the target chain health, a nil chainHealth means
+// the target chain is not monitored
+func health(logger *zap.Logger, roleProvider relay.RoleProvider, chainHealth relay.ChainHealthProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := HealthResponse{Role: roleProvider.Role()}
+		if chainHealth != nil {
+			targetChainHealth := chainHealth.TargetChainHealth()
+			response.TargetChain = &targetChainHealth
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(response)
+		if err != nil {
+			logger.Error("failed to encode health response", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+		}
+	}
+}
+
+func resubmitFailedTxs(logger *zap.Logger, store relay.Storage, txProcessor relay.TXProcessor, errorClassifier relay.ErrorClassifier, roleProvider relay.RoleProvider, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLeader(w, roleProvider) {
+			return
+		}
+
 		reqBody := ResubmitRequest{}
 		decoder := json.NewDecoder(r.Body)
 		err := decoder.Decode(&reqBody)
@@ -125,13 +207,96 @@ func resubmitFailedTxs(logger *zap.Logger, store relay.Storage, txProcessor rela
 			}
 			logger.Debug("tx", zap.Any("tx", *tx))
 			// we do not want to pass r.Context() at this place, because r.Context() is canceled at the end of the function
-			// but we have delayed call of txsubmitchecker which depends on the context passed into the ProcessAndSubmit
+			// and the submitted tx is handed over to the txsubmitchecker with the context passed into the ProcessAndSubmit
 			err = txProcessor.ProcessAndSubmit(context.Background(), txInfo.QueryID, *tx, submittedTxsTasksQueue)
 			if err != nil {
 				logger.Error("failed to process and resubmit tx", zap.Error(err))
-				http.Error(w, fmt.Sprintf("Error processing request: %s", err), http.StatusInternalServerError)
+				http.Error(w, fmt.Sprintf("Error processing request: %s", err), resubmissionErrorCode(errorClassifier, err))
 				return
 			}
 		}
 	}
 }
+
+func unsuccessfulKVs(logger *zap.Logger, storage relay.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := storage.GetAllUnsuccessfulKVs()
+		if err != nil {
+			logger.Error("failed to execute GetAllUnsuccessfulKVs", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(res)
+		if err != nil {
+			logger.Error("failed to encode result of GetAllUnsuccessfulKVs", zap.Error(err))
+			http.Error(w, "Error processing request", http.StatusInternalServerError)
+		}
+	}
+}
+
+func resubmitFailedKVs(logger *zap.Logger, store relay.Storage, kvProcessor relay.KVProcessor, errorClassifier relay.ErrorClassifier, roleProvider relay.RoleProvider, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLeader(w, roleProvider) {
+			return
+		}
+
+		reqBody := ResubmitKVRequest{}
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&reqBody)
+		if err != nil {
+			logger.Error("failed to decode request body of resubmitFailedKVs", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Error processing request: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		for _, queryID := range reqBody.QueryIDs {
+			logger.Debug("resubmitting kv", zap.Uint64("query_id", queryID))
+			msg, err := store.GetCachedKV(queryID)
+			if err != nil {
+				logger.Error("failed to get unsuccessful kv", zap.Error(err))
+				httpErrorCode := http.StatusInternalServerError
+				httpErrorMessage := fmt.Sprintf("Error processing request: %s", err)
+				if errors.Is(err, leveldb.ErrNotFound) {
+					httpErrorCode = http.StatusBadRequest
+					httpErrorMessage = fmt.Sprintf("no kv found with queryID=%d", queryID)
+				}
+				http.Error(w, httpErrorMessage, httpErrorCode)
+				return
+			}
+			// fresh values and proofs are fetched on resubmission since Neutron doesn't accept results
+			// older than the last submitted one. The update period counts from the failed submission, so
+			// it's not checked.
+			err = kvProcessor.Rebroadcast(context.Background(), msg, submittedTxsTasksQueue)
+			if err != nil {
+				logger.Error("failed to process and resubmit kv", zap.Error(err))
+				http.Error(w, fmt.Sprintf("Error processing request: %s", err), resubmissionErrorCode(errorClassifier, err))
+				return
+			}
+		}
+	}
+}
+
+// isLeader responds with an error if the relayer instance is a standby, only the leader submits txs
+func isLeader(w http.ResponseWriter, roleProvider relay.RoleProvider) bool {
+	if roleProvider.Role() != relay.RoleLeader {
+		http.Error(w, "relayer instance is a standby, resubmit to the leader", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// resubmissionErrorCode returns the http error code of a failed resubmission: retryable errors are temporary,
+// ignorable ones are not fixed by another resubmission
+func resubmissionErrorCode(errorClassifier relay.ErrorClassifier, err error) int {
+	switch errorClassifier.Classify(err) {
+	case relay.ErrorClassRetryable:
+		return http.StatusServiceUnavailable
+	case relay.ErrorClassIgnorable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
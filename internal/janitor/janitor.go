@@ -0,0 +1,220 @@
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+var (
+	// the removal tx is waited for up to commitAttempts * commitDelay
+	commitAttempts = retry.Attempts(10)
+	commitDelay    = retry.Delay(3 * time.Second)
+	commitError    = retry.LastErrorOnly(true)
+	requestTimeout = 10 * time.Second
+)
+
+// Janitor removes the registered queries whose submit timeout has passed and collects their deposits.
+// Neutron lets anyone remove such a query, the deposit is paid out to the remover. The collected deposits
+// are recorded in the storage as an audit log.
+type Janitor struct {
+	rpcClient rpcclient.Client
+	querier   relay.NeutronQuerier
+	txSender  relay.TxSender
+	storage   relay.Storage
+	// senderAddr is the address the removal transactions are signed with, it receives the deposits
+	senderAddr string
+	// batchSize is the max number of queries removed with a single transaction
+	batchSize uint64
+	// ownerDenyList contains the owners whose queries are never removed
+	ownerDenyList map[string]struct{}
+	logger        *zap.Logger
+}
+
+func NewJanitor(
+	rpcClient rpcclient.Client,
+	querier relay.NeutronQuerier,
+	txSender relay.TxSender,
+	storage relay.Storage,
+	senderAddr string,
+	batchSize uint64,
+	ownerDenyList []string,
+	logger *zap.Logger,
+) *Janitor {
+	denyList := make(map[string]struct{}, len(ownerDenyList))
+	for _, owner := range ownerDenyList {
+		denyList[owner] = struct{}{}
+	}
+
+	return &Janitor{
+		rpcClient:     rpcClient,
+		querier:       querier,
+		txSender:      txSender,
+		storage:       storage,
+		senderAddr:    senderAddr,
+		batchSize:     batchSize,
+		ownerDenyList: denyList,
+		logger:        logger,
+	}
+}
+
+// Run harvests the timed out queries periodically until the ctx is cancelled
+func (j *Janitor) Run(ctx context.Context, period time.Duration) error {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Harvest(ctx); err != nil {
+				j.logger.Error("deposits harvest failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			j.logger.Info("context cancelled, shutting down janitor...")
+			return nil
+		}
+	}
+}
+
+// Harvest removes all the timed out queries in batches
+func (j *Janitor) Harvest(ctx context.Context) error {
+	status, err := j.rpcClient.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get neutron chain status: %w", err)
+	}
+
+	queries, err := j.expiredQueries(ctx, uint64(status.SyncInfo.LatestBlockHeight))
+	if err != nil {
+		return fmt.Errorf("failed to get expired queries: %w", err)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	j.logger.Info("found timed out queries", zap.Int("count", len(queries)))
+
+	for start := 0; start < len(queries); start += int(j.batchSize) {
+		end := start + int(j.batchSize)
+		if end > len(queries) {
+			end = len(queries)
+		}
+		// a failed batch is retried on the next harvest, e.g. if one of the queries is removed by someone else
+		if err := j.removeQueries(ctx, queries[start:end]); err != nil {
+			j.logger.Error("failed to remove timed out queries", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// expiredQueries returns the queries which can be removed by anyone after the height, i.e. the queries whose
+// results haven't been submitted within the submit timeout. The queries without a deposit and the queries
+// of the denied owners are skipped.
+func (j *Janitor) expiredQueries(ctx context.Context, height uint64) ([]neutrontypes.RegisteredQuery, error) {
+	// the queries of all the owners and connections are looked through
+	queries, err := j.querier.RegisteredQueries(ctx, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registered queries: %w", err)
+	}
+
+	var out []neutrontypes.RegisteredQuery
+	for _, registeredQuery := range queries {
+		if _, ok := j.ownerDenyList[registeredQuery.Owner]; ok {
+			continue
+		}
+		if registeredQuery.Deposit.IsZero() {
+			continue
+		}
+		// Neutron allows the removal once the block height is greater than the timeout block
+		if height <= registeredQuery.LastSubmittedResultLocalHeight+registeredQuery.SubmitTimeout {
+			continue
+		}
+		out = append(out, registeredQuery)
+	}
+
+	return out, nil
+}
+
+// removeQueries removes the queries with a single transaction and records their deposits once it's committed
+func (j *Janitor) removeQueries(ctx context.Context, queries []neutrontypes.RegisteredQuery) error {
+	msgs := make([]sdk.Msg, 0, len(queries))
+	for _, registeredQuery := range queries {
+		msgs = append(msgs, &neutrontypes.MsgRemoveInterchainQueryRequest{
+			QueryId: registeredQuery.Id,
+			Sender:  j.senderAddr,
+		})
+	}
+
+	neutronHash, err := j.txSender.Send(ctx, msgs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send removal tx: %w", err)
+	}
+
+	result, err := j.waitForCommit(ctx, neutronHash)
+	if err != nil {
+		return fmt.Errorf("failed to wait for removal tx %s: %w", neutronHash, err)
+	}
+	if result.TxResult.Code != abci.CodeTypeOK {
+		return fmt.Errorf("removal tx %s failed with code=%d log=%s", neutronHash, result.TxResult.Code, result.TxResult.Log)
+	}
+
+	for _, registeredQuery := range queries {
+		deposit := relay.HarvestedDeposit{
+			QueryID:     registeredQuery.Id,
+			Owner:       registeredQuery.Owner,
+			Deposit:     registeredQuery.Deposit.String(),
+			NeutronHash: neutronHash,
+			Height:      result.Height,
+			Time:        time.Now(),
+		}
+		if err := j.storage.SaveHarvestedDeposit(&deposit); err != nil {
+			j.logger.Error("failed to save harvested deposit", zap.Error(err), zap.Uint64("query_id", registeredQuery.Id))
+		}
+		for _, coin := range registeredQuery.Deposit {
+			amount, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float64()
+			neutronmetrics.AddHarvestedDeposit(coin.Denom, amount)
+		}
+		j.logger.Info("harvested deposit of timed out query",
+			zap.Uint64("query_id", registeredQuery.Id),
+			zap.String("owner", registeredQuery.Owner),
+			zap.String("deposit", deposit.Deposit),
+			zap.String("neutron_hash", neutronHash))
+	}
+
+	return nil
+}
+
+func (j *Janitor) waitForCommit(ctx context.Context, neutronHash string) (*coretypes.ResultTx, error) {
+	hash, err := hexHash(neutronHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *coretypes.ResultTx
+	err = retry.Do(func() error {
+		timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+		var err error
+		result, err = j.rpcClient.Tx(timeoutCtx, hash, false)
+		return err
+	}, retry.Context(ctx), commitAttempts, commitDelay, commitError, retry.RetryIf(func(err error) bool {
+		return strings.Contains(err.Error(), "not found")
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
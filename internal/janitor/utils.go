@@ -0,0 +1,14 @@
+package janitor
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+func hexHash(neutronHash string) ([]byte, error) {
+	hash, err := hex.DecodeString(neutronHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tx hash %s: %w", neutronHash, err)
+	}
+	return hash, nil
+}
@@ -30,8 +30,11 @@ type KVProcessor struct {
 	logger               *zap.Logger
 	submitter            relay.Submitter
 	storage              relay.Storage
-	targetChain          *relayer.Chain
 	neutronChain         *relayer.Chain
+	// proofVerifier is used to verify proofs locally before submission. Verification is disabled if nil.
+	proofVerifier relay.ProofVerifier
+	// errorClassifier decides how to handle the submission errors, unknown errors are stored as unsuccessful
+	errorClassifier relay.ErrorClassifier
 }
 
 func NewKVProcessor(
@@ -41,8 +44,9 @@ func NewKVProcessor(
 	logger *zap.Logger,
 	submitter relay.Submitter,
 	storage relay.Storage,
-	targetChain *relayer.Chain,
-	neutronChain *relayer.Chain) *KVProcessor {
+	neutronChain *relayer.Chain,
+	proofVerifier relay.ProofVerifier,
+	errorClassifier relay.ErrorClassifier) *KVProcessor {
 	return &KVProcessor{
 		trustedHeaderFetcher: trustedHeaderFetcher,
 		querier:              querier,
@@ -50,28 +54,42 @@ func NewKVProcessor(
 		logger:               logger,
 		submitter:            submitter,
 		storage:              storage,
-		targetChain:          targetChain,
 		neutronChain:         neutronChain,
+		proofVerifier:        proofVerifier,
+		errorClassifier:      errorClassifier,
 	}
 }
 
 // ProcessAndSubmit processes relay.MessageKV. The main method which does all the work of the KVProcessor
-func (p *KVProcessor) ProcessAndSubmit(ctx context.Context, m *relay.MessageKV) error {
-	latestHeight, err := p.targetChain.ChainProvider.QueryLatestHeight(ctx)
+func (p *KVProcessor) ProcessAndSubmit(ctx context.Context, m *relay.MessageKV, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) error {
+	return p.processAndSubmit(ctx, m, submittedTxsTasksQueue, true)
+}
+
+// Rebroadcast processes relay.MessageKV regardless of the update period, the last submission of the query
+// was never committed or has failed
+func (p *KVProcessor) Rebroadcast(ctx context.Context, m *relay.MessageKV, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) error {
+	return p.processAndSubmit(ctx, m, submittedTxsTasksQueue, false)
+}
+
+func (p *KVProcessor) processAndSubmit(ctx context.Context, m *relay.MessageKV, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo, checkUpdatePeriod bool) error {
+	// queries processed in the same block are aligned to a common height to share proofs
+	latestHeight, err := p.querier.LatestHeight(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get header for src chain: %w", err)
 	}
 
-	ok, err := p.isQueryOnTime(m.QueryId, uint64(latestHeight))
-	if err != nil || !ok {
-		return fmt.Errorf("error on checking previous query update with query_id=%d: %w", m.QueryId, err)
+	if checkUpdatePeriod {
+		ok, err := p.isQueryOnTime(m.QueryId, uint64(latestHeight))
+		if err != nil || !ok {
+			return fmt.Errorf("error on checking previous query update with query_id=%d: %w", m.QueryId, err)
+		}
 	}
 
 	proofs, height, err := p.getStorageValues(ctx, uint64(latestHeight), m.KVKeys)
 	if err != nil {
 		return fmt.Errorf("failed to get storage values with proofs for query_id=%d: %w", m.QueryId, err)
 	}
-	return p.submitKVWithProof(ctx, int64(height), m.QueryId, proofs)
+	return p.submitKVWithProof(ctx, int64(height), m, proofs, submittedTxsTasksQueue)
 }
 
 // getStorageValues gets proofs for query type = 'kv'
@@ -91,7 +109,9 @@ func (p *KVProcessor) getStorageValues(ctx context.Context, inputHeight uint64,
 	return stValues, height, nil
 }
 
-// isQueryOnTime checks if query satisfies update period condition which is set by RELAYER_KV_UPDATE_PERIOD env, also modifies storage w last block
+// isQueryOnTime checks if query satisfies update period condition which is set by RELAYER_KV_UPDATE_PERIOD env.
+// The last update block is saved to the storage only after a successful submission (see submitKVWithProof),
+// so a failed attempt doesn't postpone the next one.
 func (p *KVProcessor) isQueryOnTime(queryID uint64, currentBlock uint64) (bool, error) {
 	// if it wasn't set in config
 	if p.minKVUpdatePeriod == 0 {
@@ -104,11 +124,6 @@ func (p *KVProcessor) isQueryOnTime(queryID uint64, currentBlock uint64) (bool,
 	}
 
 	if previous+p.minKVUpdatePeriod <= currentBlock {
-		err := p.storage.SetLastQueryHeight(queryID, currentBlock)
-		if err != nil {
-			return false, err
-		}
-
 		return true, nil
 	}
 
@@ -119,36 +134,103 @@ func (p *KVProcessor) isQueryOnTime(queryID uint64, currentBlock uint64) (bool,
 func (p *KVProcessor) submitKVWithProof(
 	ctx context.Context,
 	height int64,
-	queryID uint64,
+	m *relay.MessageKV,
 	proof []*neutrontypes.StorageValue,
+	submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo,
 ) error {
+	queryID := m.QueryId
 	srcHeader, err := p.getSrcChainHeader(ctx, height)
 	if err != nil {
 		return fmt.Errorf("failed to get header for height: %d: %w", height, err)
 	}
 
+	if err = p.verifyProofs(queryID, srcHeader, proof); err != nil {
+		return fmt.Errorf("failed to verify proofs for query_id=%d: %w", queryID, err)
+	}
+
 	updateClientMsg, err := p.getUpdateClientMsg(ctx, srcHeader)
 	if err != nil {
 		return fmt.Errorf("failed to getUpdateClientMsg: %w", err)
 	}
 
 	st := time.Now()
-	if err = p.submitter.SubmitKVProof(
+	neutronTxHash, err := p.submitter.SubmitKVProof(
 		ctx,
 		uint64(height-1),
 		srcHeader.GetHeight().GetRevisionNumber(),
 		queryID,
 		proof,
 		updateClientMsg,
-	); err != nil {
+	)
+	if err != nil {
 		neutronmetrics.AddFailedProof(string(neutrontypes.InterchainQueryTypeKV), time.Since(st).Seconds())
+		switch p.errorClassifier.Classify(err) {
+		case relay.ErrorClassRetryable:
+			// the query is processed again on its next update
+			return fmt.Errorf("could not submit proof (retryable): %w", err)
+		case relay.ErrorClassCritical:
+			return relay.NewErrSubmitKVProofCritical(err)
+		}
+		errSetStatus := p.storage.SetKVStatus(
+			queryID, neutronTxHash, relay.SubmittedTxInfo{Status: relay.ErrorOnSubmit, Message: err.Error()}, m)
+		if errSetStatus != nil {
+			p.logger.Error("failed to store kv submit status", zap.Uint64("query_id", queryID), zap.Error(errSetStatus))
+		}
 		return fmt.Errorf("could not submit proof: %w", err)
 	}
 	neutronmetrics.AddSuccessProof(string(neutrontypes.InterchainQueryTypeKV), time.Since(st).Seconds())
+
+	err = p.storage.SetKVStatus(queryID, neutronTxHash, relay.SubmittedTxInfo{Status: relay.Submitted}, m)
+	if err != nil {
+		return fmt.Errorf("failed to store kv submit status: %w", err)
+	}
+
+	if p.minKVUpdatePeriod != 0 {
+		if err = p.storage.SetLastQueryHeight(queryID, uint64(height)); err != nil {
+			return fmt.Errorf("failed to save last height of query: %w", err)
+		}
+	}
+
+	p.trackSubmittedTx(ctx, relay.PendingSubmittedTxInfo{
+		QueryID:     queryID,
+		NeutronHash: neutronTxHash,
+		QueryType:   string(neutrontypes.InterchainQueryTypeKV),
+	}, submittedTxsTasksQueue)
+
 	p.logger.Info("proof for query_id submitted successfully", zap.Uint64("query_id", queryID), zap.Uint64("remote_height", uint64(height-1)), zap.Uint64("trusted_header_height", srcHeader.GetHeight().GetRevisionHeight()))
 	return nil
 }
 
+// trackSubmittedTx hands the submitted tx over to the TxSubmitChecker, which resolves it as soon as
+// its block is committed.
+func (p *KVProcessor) trackSubmittedTx(ctx context.Context, tx relay.PendingSubmittedTxInfo, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) {
+	select {
+	case submittedTxsTasksQueue <- tx:
+	case <-ctx.Done():
+		p.logger.Info("Cancelled PendingSubmittedTxInfo tracking",
+			zap.Uint64("query_id", tx.QueryID),
+			zap.String("neutron_hash", tx.NeutronHash))
+	}
+}
+
+// verifyProofs checks the proofs against the AppHash of the trusted header we are about to submit.
+// Does nothing if the local proof verification is disabled.
+func (p *KVProcessor) verifyProofs(queryID uint64, srcHeader ibcexported.Header, proof []*neutrontypes.StorageValue) error {
+	if p.proofVerifier == nil {
+		return nil
+	}
+
+	if err := p.proofVerifier.VerifyKVProofs(srcHeader, proof); err != nil {
+		neutronmetrics.IncFailedProofVerification(string(neutrontypes.InterchainQueryTypeKV))
+		p.logger.Error("proof verification failed, rejecting query result",
+			zap.Uint64("query_id", queryID), zap.Uint64("trusted_header_height", srcHeader.GetHeight().GetRevisionHeight()), zap.Error(err))
+		return err
+	}
+	neutronmetrics.IncSuccessProofVerification(string(neutrontypes.InterchainQueryTypeKV))
+
+	return nil
+}
+
 func (p *KVProcessor) getSrcChainHeader(ctx context.Context, height int64) (ibcexported.Header, error) {
 	start := time.Now()
 	var srcHeader ibcexported.Header
@@ -0,0 +1,157 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// releaseTimeout is the time limit to release the lease on shutdown
+const releaseTimeout = 5 * time.Second
+
+// ErrLeadershipLost is returned by Elector.Run when the lease is taken over by another instance or can't
+// be renewed in time. The instance has to stop submitting right away since a standby is about to take over.
+var ErrLeadershipLost = errors.New("leadership lost")
+
+// Elector elects the leader among the relayer instances sharing the lease. The lease is renewed three times
+// per ttl, and the leader steps down if it fails to renew the lease for two thirds of the ttl, i.e. before
+// a standby is allowed to take it over.
+type Elector struct {
+	// lease is nil if the high availability mode is disabled, the instance is always the leader then
+	lease  relay.Lease
+	holder string
+	ttl    time.Duration
+	logger *zap.Logger
+
+	// leader is 1 if the instance holds the lease
+	leader uint32
+	// deposed is closed once the instance loses the lease
+	deposed chan struct{}
+	// released is closed by Release once the instance stops submitting on shutdown
+	released    chan struct{}
+	releaseOnce sync.Once
+}
+
+// NewElector constructs a new Elector of the holder. A nil lease makes the instance the leader right away.
+func NewElector(lease relay.Lease, holder string, ttl time.Duration, logger *zap.Logger) *Elector {
+	return &Elector{
+		lease:    lease,
+		holder:   holder,
+		ttl:      ttl,
+		logger:   logger,
+		deposed:  make(chan struct{}),
+		released: make(chan struct{}),
+	}
+}
+
+// Deposed returns a channel closed once the instance loses the leadership, it's closed before Run returns
+// ErrLeadershipLost. Unlike the shutdown, the leadership loss requires the submissions to stop right away.
+func (e *Elector) Deposed() <-chan struct{} {
+	return e.deposed
+}
+
+// Role implements relay.RoleProvider
+func (e *Elector) Role() string {
+	if e.isLeader() {
+		return relay.RoleLeader
+	}
+	return relay.RoleStandby
+}
+
+// Run keeps trying to acquire the lease and closes the elected channel once the instance becomes the leader.
+// Then it keeps renewing the lease until Release is called after the ctx is cancelled, or returns
+// ErrLeadershipLost if the lease is lost. The standby returns as soon as the ctx is cancelled.
+func (e *Elector) Run(ctx context.Context, elected chan<- struct{}) error {
+	neutronmetrics.SetLeader(false)
+	if e.lease == nil {
+		e.becomeLeader(elected)
+		return nil
+	}
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	var renewedAt time.Time
+	shutdown := ctx.Done()
+	for {
+		acquired, err := e.lease.TryAcquire(ctx, e.holder, e.ttl)
+		switch {
+		case err != nil:
+			e.logger.Error("failed to acquire lease", zap.Error(err))
+		case acquired:
+			renewedAt = time.Now()
+			if !e.isLeader() {
+				e.becomeLeader(elected)
+			}
+		case e.isLeader():
+			e.logger.Error("lease is taken over by another instance")
+			e.stepDown()
+			return ErrLeadershipLost
+		}
+
+		if e.isLeader() && time.Since(renewedAt) >= e.ttl*2/3 {
+			e.logger.Error("failed to renew lease in time", zap.Time("renewed_at", renewedAt))
+			e.stepDown()
+			return ErrLeadershipLost
+		}
+
+		select {
+		case <-ticker.C:
+		case <-e.released:
+			e.release()
+			return nil
+		case <-shutdown:
+			if !e.isLeader() {
+				return nil
+			}
+			// the task in progress is still being drained, so the lease is renewed until it's released,
+			// otherwise a standby could take over and submit with the same key concurrently
+			shutdown = nil
+			ctx = context.Background()
+		}
+	}
+}
+
+// Release makes Run release the lease and return, it has to be called once the instance stops submitting
+// on shutdown
+func (e *Elector) Release() {
+	e.releaseOnce.Do(func() {
+		close(e.released)
+	})
+}
+
+func (e *Elector) isLeader() bool {
+	return atomic.LoadUint32(&e.leader) == 1
+}
+
+func (e *Elector) becomeLeader(elected chan<- struct{}) {
+	atomic.StoreUint32(&e.leader, 1)
+	neutronmetrics.SetLeader(true)
+	e.logger.Info("relayer instance is elected the leader", zap.String("holder", e.holder))
+	close(elected)
+}
+
+func (e *Elector) stepDown() {
+	atomic.StoreUint32(&e.leader, 0)
+	neutronmetrics.SetLeader(false)
+	close(e.deposed)
+}
+
+func (e *Elector) release() {
+	if !e.isLeader() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := e.lease.Release(ctx, e.holder); err != nil {
+		e.logger.Error("failed to release lease", zap.Error(err))
+	}
+}
@@ -0,0 +1,90 @@
+package leader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestElectorKeepsLeaseUntilReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	ttl := 300 * time.Millisecond
+	elector := NewElector(NewFileLease(path), "leader", ttl, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	elected := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- elector.Run(ctx, elected)
+	}()
+
+	select {
+	case <-elected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("elector has not been elected")
+	}
+
+	// the shutdown starts, the task in progress is drained for longer than the ttl
+	cancel()
+	standby := NewFileLease(path)
+	deadline := time.Now().Add(3 * ttl)
+	for time.Now().Before(deadline) {
+		acquired, err := standby.TryAcquire(context.Background(), "standby", ttl)
+		if err != nil {
+			t.Fatalf("failed to acquire lease: %v", err)
+		}
+		if acquired {
+			t.Fatalf("standby took the lease over while the leader is draining")
+		}
+		time.Sleep(ttl / 10)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("elector returned before the release: %v", err)
+	default:
+	}
+
+	elector.Release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("elector returned an error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("elector has not returned after the release")
+	}
+
+	acquired, err := standby.TryAcquire(context.Background(), "standby", ttl)
+	if err != nil || !acquired {
+		t.Fatalf("expected the standby to take the released lease over, got %v, %v", acquired, err)
+	}
+}
+
+func TestElectorStandbyReturnsOnShutdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	ttl := time.Minute
+	if acquired, err := NewFileLease(path).TryAcquire(context.Background(), "leader", ttl); err != nil || !acquired {
+		t.Fatalf("expected the leader to acquire the lease, got %v, %v", acquired, err)
+	}
+
+	elector := NewElector(NewFileLease(path), "standby", ttl, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- elector.Run(ctx, make(chan struct{}))
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("elector returned an error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("standby elector has not returned on shutdown")
+	}
+}
@@ -0,0 +1,124 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileLeaseRecord is the content of the lease file
+type fileLeaseRecord struct {
+	Holder    string    `json:"holder"`
+	RenewedAt time.Time `json:"renewed_at"`
+}
+
+// FileLease is a relay.Lease kept in a file on a volume shared by the relayer instances. The lease file is read
+// and replaced under an exclusive flock(2) of a lock file next to it, so the volume must support flock (e.g. a
+// local disk or NFSv4), and the instances are expected to have their clocks synchronized.
+type FileLease struct {
+	path     string
+	lockPath string
+}
+
+func NewFileLease(path string) *FileLease {
+	return &FileLease{path: path, lockPath: path + ".lock"}
+}
+
+// TryAcquire implements relay.Lease
+func (l *FileLease) TryAcquire(_ context.Context, holder string, ttl time.Duration) (bool, error) {
+	unlock, err := l.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	record, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if record != nil && record.Holder != holder && time.Now().Before(record.RenewedAt.Add(ttl)) {
+		return false, nil
+	}
+
+	if err := l.write(fileLeaseRecord{Holder: holder, RenewedAt: time.Now()}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release implements relay.Lease
+func (l *FileLease) Release(_ context.Context, holder string) error {
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	record, err := l.read()
+	if err != nil {
+		return err
+	}
+	if record == nil || record.Holder != holder {
+		return nil
+	}
+
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lease file: %w", err)
+	}
+	return nil
+}
+
+// lock takes the exclusive lock of the lease file, the lock is released by the kernel if the process dies
+func (l *FileLease) lock() (func(), error) {
+	f, err := os.OpenFile(l.lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lease lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock lease lock file: %w", err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// read returns nil if there is no lease file
+func (l *FileLease) read() (*fileLeaseRecord, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read lease file: %w", err)
+	}
+
+	var record fileLeaseRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lease file: %w", err)
+	}
+	return &record, nil
+}
+
+// write replaces the lease file atomically with a rename, so a crash never leaves a partially written record
+func (l *FileLease) write(record fileLeaseRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease record: %w", err)
+	}
+
+	tmpPath := fmt.Sprintf("%s.%s.tmp", l.path, record.Holder)
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write lease file: %w", err)
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return fmt.Errorf("failed to replace lease file: %w", err)
+	}
+	return nil
+}
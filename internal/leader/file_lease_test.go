@@ -0,0 +1,79 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileLeaseTryAcquireConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	const holders = 32
+
+	for round := 0; round < 20; round++ {
+		// every instance competes for the lease with its own FileLease like separate processes do
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			winners []string
+			start   = make(chan struct{})
+		)
+		for i := 0; i < holders; i++ {
+			holder := fmt.Sprintf("holder-%d-%d", round, i)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				acquired, err := NewFileLease(path).TryAcquire(context.Background(), holder, time.Minute)
+				if err != nil {
+					t.Errorf("failed to acquire lease: %v", err)
+					return
+				}
+				if acquired {
+					mu.Lock()
+					winners = append(winners, holder)
+					mu.Unlock()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if len(winners) != 1 {
+			t.Fatalf("round %d: expected exactly one holder to acquire the lease, got %v", round, winners)
+		}
+		if err := NewFileLease(path).Release(context.Background(), winners[0]); err != nil {
+			t.Fatalf("failed to release lease: %v", err)
+		}
+	}
+}
+
+func TestFileLeaseRenewAndTakeOver(t *testing.T) {
+	ctx := context.Background()
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease"))
+	ttl := 50 * time.Millisecond
+
+	if acquired, err := lease.TryAcquire(ctx, "a", ttl); err != nil || !acquired {
+		t.Fatalf("expected a to acquire the lease, got %v, %v", acquired, err)
+	}
+	if acquired, err := lease.TryAcquire(ctx, "a", ttl); err != nil || !acquired {
+		t.Fatalf("expected a to renew the lease, got %v, %v", acquired, err)
+	}
+	if acquired, err := lease.TryAcquire(ctx, "b", ttl); err != nil || acquired {
+		t.Fatalf("expected b not to acquire the lease held by a, got %v, %v", acquired, err)
+	}
+
+	time.Sleep(2 * ttl)
+	if acquired, err := lease.TryAcquire(ctx, "b", ttl); err != nil || !acquired {
+		t.Fatalf("expected b to take the expired lease over, got %v, %v", acquired, err)
+	}
+	if err := lease.Release(ctx, "a"); err != nil {
+		t.Fatalf("failed to release lease: %v", err)
+	}
+	if acquired, err := lease.TryAcquire(ctx, "a", ttl); err != nil || acquired {
+		t.Fatalf("expected the release by a former holder to keep the lease of b, got %v, %v", acquired, err)
+	}
+}
@@ -1,15 +1,30 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 const (
-	labelMethod = "method"
-	labelType   = "type"
-	typeSuccess = "success"
-	typeFailed  = "failed"
+	labelMethod    = "method"
+	labelType      = "type"
+	labelGranter   = "granter"
+	labelDenom     = "denom"
+	labelQueryType = "query_type"
+	typeSuccess    = "success"
+	typeFailed     = "failed"
+	typeHit        = "hit"
+	typeMiss       = "miss"
+	typeExpired    = "expired"
+	typeWon        = "won"
+	typeAdded      = "added"
+	typeRemoved    = "removed"
+	typeUpdated    = "updated"
+	typeLost       = "lost"
+	// typeRebroadcast counts the submitted txs evicted from the mempool and rebroadcast
+	typeRebroadcast = "rebroadcast"
 )
 
 var (
@@ -51,11 +66,126 @@ var (
 		Help: "The total number of unsuccessful txs in the storage",
 	})
 
+	unsuccessfulKVsQueueSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "unsuccessful_kvs",
+		Help: "The total number of queries with unsuccessful KV results submission in the storage",
+	})
+
 	subscriberTaskQueueNumElements = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "subscriber_task_queue_num_elements",
 		Help: "The total number of elements in Subscriber's task queue",
 	}, []string{})
 
+	proofVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "proof_verifications",
+		Help: "The total number of local proof verifications before submission (counter)",
+	}, []string{labelMethod, labelType})
+
+	grantAvailable = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grant_available",
+		Help: "Whether the fee or authz grant to the relayer is available (1) or not (0)",
+	}, []string{labelGranter, labelType})
+
+	feeGrantAllowance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fee_grant_allowance",
+		Help: "The remaining spend limit of the fee grant to the relayer, not set if the allowance is unlimited",
+	}, []string{labelGranter, labelDenom})
+
+	gasPrice = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gas_price",
+		Help: "The effective gas price the transactions are sent with",
+	}, []string{labelDenom})
+
+	feesSpent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fees_spent",
+		Help: "The total amount of fees of the broadcast transactions (counter)",
+	}, []string{labelDenom})
+
+	gasEstimations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gas_estimations",
+		Help: "The total number of gas profile lookups, a hit skips the transaction simulation (counter)",
+	}, []string{labelType})
+
+	quorumChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "quorum_checks",
+		Help: "The total number of target chain responses cross-checked with the quorum (counter)",
+	}, []string{labelMethod, labelType})
+
+	quorumIncidents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "quorum_incidents",
+		Help: "The total number of disagreements between target chain nodes (counter)",
+	}, []string{labelMethod})
+
+	kvProofCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kv_proof_cache_lookups",
+		Help: "The total number of KV proof cache lookups (counter)",
+	}, []string{labelType})
+
+	kvProofCacheHitRatio = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kv_proof_cache_hit_ratio",
+		Help: "The ratio of KV proof cache hits to the total number of KV proof cache lookups",
+	})
+
+	harvestedDeposits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "harvested_deposits",
+		Help: "The total amount of deposits collected by removing timed out queries (counter)",
+	}, []string{labelDenom})
+
+	queriesDrift = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "queries_drift",
+		Help: "The total number of active queries fixed by the reconciliation with Neutron, i.e. the missed query events (counter)",
+	}, []string{labelType})
+
+	querySubmissions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "query_submissions",
+		Help: "The total number of query results accepted by Neutron, won if submitted by the relayer and lost if by another one (counter)",
+	}, []string{labelQueryType, labelType})
+
+	leader = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "leader",
+		Help: "Whether the relayer instance holds the lease (1) or is a standby (0)",
+	})
+
+	targetChainRevision = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "target_chain_revision",
+		Help: "The revision number of the target chain ID the relayer works with",
+	})
+
+	clientTrustRemaining = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "client_trust_remaining_seconds",
+		Help: "The time left until the Neutron light client of the target chain expires",
+	})
+
+	clientFrozen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "client_frozen",
+		Help: "Whether the Neutron light client of the target chain is frozen (1) or not (0)",
+	})
+
+	clientRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "client_refreshes",
+		Help: "The total number of the standalone light client updates submitted to keep the client from expiring (counter)",
+	}, []string{labelType})
+
+	targetChainHealthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "target_chain_healthy",
+		Help: "Whether the target chain is healthy (1) or has halted or the target node lags behind (0), the queries processing is paused then",
+	})
+
+	targetChainBlockAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "target_chain_block_age_seconds",
+		Help: "The time passed since the latest block of the target node",
+	})
+
+	targetChainHeightLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "target_chain_height_lag",
+		Help: "The number of blocks the target node is behind the other configured target nodes",
+	})
+
+	pausedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "paused_requests",
+		Help: "The total number of the query tasks skipped while the target chain is unhealthy (counter)",
+	}, []string{labelType})
+
 	queriesToProcess = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "queries_to_process",
 		Help: "The total number of active registered queries to process (counter)",
@@ -137,10 +267,26 @@ func IncFailedTxSubmit() {
 	}).Inc()
 }
 
+func IncExpiredTxSubmit() {
+	submittedTxCounter.With(prometheus.Labels{
+		labelType: typeExpired,
+	}).Inc()
+}
+
+func IncRebroadcastTxSubmit() {
+	submittedTxCounter.With(prometheus.Labels{
+		labelType: typeRebroadcast,
+	}).Inc()
+}
+
 func SetUnsuccessfulTxsSizeQueue(size int) {
 	unsuccessfulTxsQueueSize.Set(float64(size))
 }
 
+func SetUnsuccessfulKVsSizeQueue(size int) {
+	unsuccessfulKVsQueueSize.Set(float64(size))
+}
+
 func SetSubscriberTaskQueueNumElements(numElements int) {
 	subscriberTaskQueueNumElements.With(prometheus.Labels{}).Set(float64(numElements))
 }
@@ -148,3 +294,191 @@ func SetSubscriberTaskQueueNumElements(numElements int) {
 func SetQueriesToProcessNumElements(numElements int) {
 	queriesToProcess.With(prometheus.Labels{}).Set(float64(numElements))
 }
+
+func IncSuccessProofVerification(queryType string) {
+	proofVerifications.With(prometheus.Labels{
+		labelMethod: queryType,
+		labelType:   typeSuccess,
+	}).Inc()
+}
+
+func IncFailedProofVerification(queryType string) {
+	proofVerifications.With(prometheus.Labels{
+		labelMethod: queryType,
+		labelType:   typeFailed,
+	}).Inc()
+}
+
+func IncSuccessQuorumCheck(method string) {
+	quorumChecks.With(prometheus.Labels{
+		labelMethod: method,
+		labelType:   typeSuccess,
+	}).Inc()
+}
+
+func IncFailedQuorumCheck(method string) {
+	quorumChecks.With(prometheus.Labels{
+		labelMethod: method,
+		labelType:   typeFailed,
+	}).Inc()
+}
+
+func IncQuorumIncidents(method string) {
+	quorumIncidents.With(prometheus.Labels{
+		labelMethod: method,
+	}).Inc()
+}
+
+func IncKVProofCacheHit() {
+	kvProofCacheLookups.With(prometheus.Labels{
+		labelType: typeHit,
+	}).Inc()
+}
+
+func IncKVProofCacheMiss() {
+	kvProofCacheLookups.With(prometheus.Labels{
+		labelType: typeMiss,
+	}).Inc()
+}
+
+func SetKVProofCacheHitRatio(ratio float64) {
+	kvProofCacheHitRatio.Set(ratio)
+}
+
+func SetGrantAvailable(granter string, grantType string, available bool) {
+	value := 0.0
+	if available {
+		value = 1
+	}
+	grantAvailable.With(prometheus.Labels{
+		labelGranter: granter,
+		labelType:    grantType,
+	}).Set(value)
+}
+
+func SetFeeGrantAllowance(granter string, denom string, amount float64) {
+	feeGrantAllowance.With(prometheus.Labels{
+		labelGranter: granter,
+		labelDenom:   denom,
+	}).Set(amount)
+}
+
+func SetGasPrice(denom string, price float64) {
+	gasPrice.With(prometheus.Labels{
+		labelDenom: denom,
+	}).Set(price)
+}
+
+func AddFeesSpent(denom string, amount float64) {
+	feesSpent.With(prometheus.Labels{
+		labelDenom: denom,
+	}).Add(amount)
+}
+
+func IncGasEstimationHit() {
+	gasEstimations.With(prometheus.Labels{
+		labelType: typeHit,
+	}).Inc()
+}
+
+func IncGasEstimationMiss() {
+	gasEstimations.With(prometheus.Labels{
+		labelType: typeMiss,
+	}).Inc()
+}
+
+func SetLeader(isLeader bool) {
+	value := 0.0
+	if isLeader {
+		value = 1
+	}
+	leader.Set(value)
+}
+
+func SetTargetChainRevision(revision uint64) {
+	targetChainRevision.Set(float64(revision))
+}
+
+func SetClientTrustRemaining(remaining time.Duration) {
+	clientTrustRemaining.Set(remaining.Seconds())
+}
+
+func SetClientFrozen(frozen bool) {
+	value := 0.0
+	if frozen {
+		value = 1
+	}
+	clientFrozen.Set(value)
+}
+
+func IncClientRefreshSuccess() {
+	clientRefreshes.With(prometheus.Labels{
+		labelType: typeSuccess,
+	}).Inc()
+}
+
+func IncClientRefreshFailed() {
+	clientRefreshes.With(prometheus.Labels{
+		labelType: typeFailed,
+	}).Inc()
+}
+
+func SetTargetChainHealthy(healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	targetChainHealthy.Set(value)
+}
+
+func SetTargetChainBlockAge(age time.Duration) {
+	targetChainBlockAge.Set(age.Seconds())
+}
+
+func SetTargetChainHeightLag(lag uint64) {
+	targetChainHeightLag.Set(float64(lag))
+}
+
+func IncPausedRequest(message string) {
+	pausedRequests.With(prometheus.Labels{
+		labelType: message,
+	}).Inc()
+}
+
+func IncQuerySubmissionWon(queryType string) {
+	querySubmissions.With(prometheus.Labels{
+		labelQueryType: queryType,
+		labelType:      typeWon,
+	}).Inc()
+}
+
+func IncQuerySubmissionLost(queryType string) {
+	querySubmissions.With(prometheus.Labels{
+		labelQueryType: queryType,
+		labelType:      typeLost,
+	}).Inc()
+}
+
+func AddHarvestedDeposit(denom string, amount float64) {
+	harvestedDeposits.With(prometheus.Labels{
+		labelDenom: denom,
+	}).Add(amount)
+}
+
+func IncQueriesDriftAdded() {
+	queriesDrift.With(prometheus.Labels{
+		labelType: typeAdded,
+	}).Inc()
+}
+
+func IncQueriesDriftRemoved() {
+	queriesDrift.With(prometheus.Labels{
+		labelType: typeRemoved,
+	}).Inc()
+}
+
+func IncQueriesDriftUpdated() {
+	queriesDrift.With(prometheus.Labels{
+		labelType: typeUpdated,
+	}).Inc()
+}
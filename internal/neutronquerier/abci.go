@@ -0,0 +1,83 @@
+package neutronquerier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types/query"
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/raw"
+)
+
+const (
+	registeredQueryQueryPath   = "/neutron.interchainqueries.Query/RegisteredQuery"
+	registeredQueriesQueryPath = "/neutron.interchainqueries.Query/RegisteredQueries"
+	connectionQueryPath        = "/ibc.core.connection.v1.Query/Connection"
+)
+
+// ABCIQuerier is a relay.NeutronQuerier making the gRPC queries over the ABCI query of the Neutron RPC node
+type ABCIQuerier struct {
+	rpcClient rpcclient.Client
+}
+
+func NewABCIQuerier(rpcClient rpcclient.Client) *ABCIQuerier {
+	return &ABCIQuerier{rpcClient: rpcClient}
+}
+
+// RegisteredQuery implements relay.NeutronQuerier
+func (q *ABCIQuerier) RegisteredQuery(ctx context.Context, queryID uint64) (*neutrontypes.RegisteredQuery, error) {
+	request := neutrontypes.QueryRegisteredQueryRequest{QueryId: queryID}
+	var response neutrontypes.QueryRegisteredQueryResponse
+	if err := raw.ABCIQuery(ctx, q.rpcClient, registeredQueryQueryPath, &request, &response); err != nil {
+		return nil, err
+	}
+	if response.RegisteredQuery == nil {
+		return nil, fmt.Errorf("query %d not found", queryID)
+	}
+
+	return response.RegisteredQuery, nil
+}
+
+// RegisteredQueries implements relay.NeutronQuerier
+func (q *ABCIQuerier) RegisteredQueries(ctx context.Context, owners []string, connectionID string) ([]neutrontypes.RegisteredQuery, error) {
+	var (
+		out     []neutrontypes.RegisteredQuery
+		pageKey []byte
+	)
+	for {
+		request := neutrontypes.QueryRegisteredQueriesRequest{
+			Owners:       owners,
+			ConnectionId: connectionID,
+			Pagination:   &query.PageRequest{Key: pageKey},
+		}
+		var response neutrontypes.QueryRegisteredQueriesResponse
+		if err := raw.ABCIQuery(ctx, q.rpcClient, registeredQueriesQueryPath, &request, &response); err != nil {
+			return nil, err
+		}
+		out = append(out, response.RegisteredQueries...)
+
+		if response.Pagination == nil || len(response.Pagination.NextKey) == 0 {
+			break
+		}
+		pageKey = response.Pagination.NextKey
+	}
+
+	return out, nil
+}
+
+// Connection implements relay.NeutronQuerier
+func (q *ABCIQuerier) Connection(ctx context.Context, connectionID string) (*connectiontypes.ConnectionEnd, error) {
+	request := connectiontypes.QueryConnectionRequest{ConnectionId: connectionID}
+	var response connectiontypes.QueryConnectionResponse
+	if err := raw.ABCIQuery(ctx, q.rpcClient, connectionQueryPath, &request, &response); err != nil {
+		return nil, err
+	}
+	if response.Connection == nil {
+		return nil, fmt.Errorf("connection %s not found", connectionID)
+	}
+
+	return response.Connection, nil
+}
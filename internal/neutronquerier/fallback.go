@@ -0,0 +1,57 @@
+package neutronquerier
+
+import (
+	"context"
+
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	"go.uber.org/zap"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// FallbackQuerier is a relay.NeutronQuerier making the queries with the primary querier and falling back
+// to the secondary one if the primary fails, e.g. the ABCI queries and the REST gateway respectively
+type FallbackQuerier struct {
+	primary   relay.NeutronQuerier
+	secondary relay.NeutronQuerier
+	logger    *zap.Logger
+}
+
+func NewFallbackQuerier(primary relay.NeutronQuerier, secondary relay.NeutronQuerier, logger *zap.Logger) *FallbackQuerier {
+	return &FallbackQuerier{
+		primary:   primary,
+		secondary: secondary,
+		logger:    logger,
+	}
+}
+
+// RegisteredQuery implements relay.NeutronQuerier
+func (q *FallbackQuerier) RegisteredQuery(ctx context.Context, queryID uint64) (*neutrontypes.RegisteredQuery, error) {
+	res, err := q.primary.RegisteredQuery(ctx, queryID)
+	if err != nil {
+		q.logger.Warn("failed to get registered query, falling back", zap.Error(err))
+		return q.secondary.RegisteredQuery(ctx, queryID)
+	}
+	return res, nil
+}
+
+// RegisteredQueries implements relay.NeutronQuerier
+func (q *FallbackQuerier) RegisteredQueries(ctx context.Context, owners []string, connectionID string) ([]neutrontypes.RegisteredQuery, error) {
+	res, err := q.primary.RegisteredQueries(ctx, owners, connectionID)
+	if err != nil {
+		q.logger.Warn("failed to get registered queries, falling back", zap.Error(err))
+		return q.secondary.RegisteredQueries(ctx, owners, connectionID)
+	}
+	return res, nil
+}
+
+// Connection implements relay.NeutronQuerier
+func (q *FallbackQuerier) Connection(ctx context.Context, connectionID string) (*connectiontypes.ConnectionEnd, error) {
+	res, err := q.primary.Connection(ctx, connectionID)
+	if err != nil {
+		q.logger.Warn("failed to get connection, falling back", zap.Error(err))
+		return q.secondary.Connection(ctx, connectionID)
+	}
+	return res, nil
+}
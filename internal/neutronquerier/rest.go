@@ -0,0 +1,106 @@
+package neutronquerier
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	"github.com/go-openapi/strfmt"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+
+	restclient "github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client"
+	"github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client/query"
+)
+
+// RESTQuerier is a relay.NeutronQuerier using the REST gateway of Neutron. The REST models lack some of
+// the query fields (e.g. the deposit and the submit timeout), so they are left empty.
+type RESTQuerier struct {
+	restClient *restclient.HTTPAPIConsole
+}
+
+func NewRESTQuerier(restClient *restclient.HTTPAPIConsole) *RESTQuerier {
+	return &RESTQuerier{restClient: restClient}
+}
+
+// RegisteredQuery implements relay.NeutronQuerier
+func (q *RESTQuerier) RegisteredQuery(ctx context.Context, queryID uint64) (*neutrontypes.RegisteredQuery, error) {
+	id := strconv.FormatUint(queryID, 10)
+	res, err := q.restClient.Query.NeutronInterchainQueriesRegisteredQuery(
+		&query.NeutronInterchainQueriesRegisteredQueryParams{
+			QueryID: &id,
+			Context: ctx,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NeutronInterchainqueriesRegisteredQuery: %w", err)
+	}
+	neutronQuery, err := res.GetPayload().RegisteredQuery.ToNeutronRegisteredQuery()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neutronQueryFromRestQuery: %w", err)
+	}
+	return neutronQuery, nil
+}
+
+// RegisteredQueries implements relay.NeutronQuerier
+func (q *RESTQuerier) RegisteredQueries(ctx context.Context, owners []string, connectionID string) ([]neutrontypes.RegisteredQuery, error) {
+	var (
+		out     []neutrontypes.RegisteredQuery
+		pageKey *strfmt.Base64
+	)
+	for {
+		res, err := q.restClient.Query.NeutronInterchainQueriesRegisteredQueries(
+			&query.NeutronInterchainQueriesRegisteredQueriesParams{
+				Owners:        owners,
+				ConnectionID:  &connectionID,
+				Context:       ctx,
+				PaginationKey: pageKey,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get NeutronInterchainqueriesRegisteredQueries: %w", err)
+		}
+
+		payload := res.GetPayload()
+		for _, restQuery := range payload.RegisteredQueries {
+			neutronQuery, err := restQuery.ToNeutronRegisteredQuery()
+			if err != nil {
+				return nil, fmt.Errorf("failed to cast ToNeutronRegisteredQuery: %w", err)
+			}
+			out = append(out, *neutronQuery)
+		}
+
+		if payload.Pagination != nil && payload.Pagination.NextKey.String() != "" {
+			pageKey = &payload.Pagination.NextKey
+		} else {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// Connection implements relay.NeutronQuerier
+func (q *RESTQuerier) Connection(ctx context.Context, connectionID string) (*connectiontypes.ConnectionEnd, error) {
+	res, err := q.restClient.Query.IbcCoreConnectionV1Connection(&query.IbcCoreConnectionV1ConnectionParams{
+		ConnectionID: connectionID,
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IbcCoreConnectionV1Connection: %w", err)
+	}
+
+	connection := res.GetPayload().Connection
+	if connection == nil {
+		return nil, fmt.Errorf("connection %s not found", connectionID)
+	}
+	out := connectiontypes.ConnectionEnd{ClientId: connection.ClientID}
+	if connection.Counterparty != nil {
+		out.Counterparty = connectiontypes.Counterparty{
+			ClientId:     connection.Counterparty.ClientID,
+			ConnectionId: connection.Counterparty.ConnectionID,
+		}
+	}
+
+	return &out, nil
+}
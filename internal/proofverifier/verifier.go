@@ -0,0 +1,101 @@
+package proofverifier
+
+import (
+	"fmt"
+	"net/url"
+
+	commitmenttypes "github.com/cosmos/ibc-go/v4/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+
+	icqkeeper "github.com/neutron-org/neutron/x/interchainqueries/keeper"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+)
+
+// ProofVerifier is implementation of relay.ProofVerifier. It repeats the checks the Neutron's
+// interchainqueries module does on MsgSubmitQueryResult, so a result that would be rejected
+// on-chain never gets submitted.
+type ProofVerifier struct {
+	txVerifier icqkeeper.TransactionVerifier
+}
+
+// NewProofVerifier constructs a new ProofVerifier
+func NewProofVerifier() *ProofVerifier {
+	return &ProofVerifier{txVerifier: icqkeeper.TransactionVerifier{}}
+}
+
+// VerifyKVProofs verifies ICS23 proofs of the values against the AppHash of the given header.
+// Note: the AppHash of a header at height H commits the state at height H-1, which is exactly
+// the height the values are queried at (see tmquerier.Querier.QueryTendermintProof).
+func (v *ProofVerifier) VerifyKVProofs(header ibcexported.Header, values []*neutrontypes.StorageValue) error {
+	tmHeader, err := toTmHeader(header)
+	if err != nil {
+		return err
+	}
+
+	root := commitmenttypes.NewMerkleRoot(tmHeader.Header.AppHash)
+	for _, value := range values {
+		proof, err := commitmenttypes.ConvertProofs(value.Proof)
+		if err != nil {
+			return fmt.Errorf("failed to convert crypto.ProofOps to MerkleProof for path=%s and key=%v: %w", value.StoragePrefix, value.Key, err)
+		}
+		if len(proof.GetProofs()) == 0 {
+			return fmt.Errorf("empty proof for path=%s and key=%v", value.StoragePrefix, value.Key)
+		}
+
+		path := commitmenttypes.NewMerklePath(value.StoragePrefix, url.PathEscape(string(value.Key)))
+
+		// non-existence proof always has *ics23.CommitmentProof_Nonexist as the first item
+		first := proof.GetProofs()[0]
+		switch {
+		case first.GetNonexist() != nil:
+			if err := proof.VerifyNonMembership(commitmenttypes.GetSDKSpecs(), root, path); err != nil {
+				return fmt.Errorf("failed to verify non-membership proof for path=%s and key=%v: %w", value.StoragePrefix, value.Key, err)
+			}
+		case first.GetExist() != nil:
+			if err := proof.VerifyMembership(commitmenttypes.GetSDKSpecs(), root, path, value.Value); err != nil {
+				return fmt.Errorf("failed to verify membership proof for path=%s and key=%v: %w", value.StoragePrefix, value.Key, err)
+			}
+		default:
+			return fmt.Errorf("unknown proof type %T for path=%s and key=%v", first.GetProof(), value.StoragePrefix, value.Key)
+		}
+	}
+
+	return nil
+}
+
+// VerifyTxProof verifies that the tx is included in the block of the header (against DataHash) and
+// that the tx response is delivered (against LastResultsHash of the nextHeader).
+func (v *ProofVerifier) VerifyTxProof(header ibcexported.Header, nextHeader ibcexported.Header, tx *neutrontypes.TxValue) error {
+	tmHeader, err := toTmHeader(header)
+	if err != nil {
+		return err
+	}
+
+	tmNextHeader, err := toTmHeader(nextHeader)
+	if err != nil {
+		return err
+	}
+
+	if tmNextHeader.Header.Height != tmHeader.Header.Height+1 {
+		return fmt.Errorf("nextHeader.Height is not header.Height+1: %d != %d+1", tmNextHeader.Header.Height, tmHeader.Header.Height)
+	}
+
+	if err := v.txVerifier.VerifyTransaction(tmHeader, tmNextHeader, tx); err != nil {
+		return fmt.Errorf("failed to verify transaction: %w", err)
+	}
+
+	return nil
+}
+
+func toTmHeader(header ibcexported.Header) (*tmclient.Header, error) {
+	tmHeader, ok := header.(*tmclient.Header)
+	if !ok {
+		return nil, fmt.Errorf("expected header of type *tmclient.Header, got %T", header)
+	}
+	if tmHeader.Header == nil {
+		return nil, fmt.Errorf("got header without tendermint header")
+	}
+
+	return tmHeader, nil
+}
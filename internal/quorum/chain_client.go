@@ -0,0 +1,37 @@
+package quorum
+
+import (
+	"context"
+	"fmt"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// ChainClient is a relay.ChainClient that cross-checks block results with the quorum. Found txs
+// don't need to be cross-checked since their inclusion is proven against the trusted headers,
+// while the block results are what the delivery proofs are built from.
+type ChainClient struct {
+	relay.ChainClient
+	checker *Checker
+}
+
+// NewChainClient wraps the primary node chainClient into the quorum ChainClient
+func NewChainClient(chainClient relay.ChainClient, checker *Checker) *ChainClient {
+	return &ChainClient{ChainClient: chainClient, checker: checker}
+}
+
+// BlockResults returns the primary node block results if the quorum agrees with them
+func (c *ChainClient) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	res, err := c.ChainClient.BlockResults(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checker.CheckBlockResults(ctx, res.Height, res.TxsResults); err != nil {
+		return nil, fmt.Errorf("failed to cross-check block results: %w", err)
+	}
+
+	return res, nil
+}
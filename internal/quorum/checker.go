@@ -0,0 +1,186 @@
+package quorum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"go.uber.org/zap"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+const (
+	methodABCIQuery    = "abci_query"
+	methodBlockResults = "block_results"
+)
+
+// Node is a target chain node participating in the quorum cross-checks
+type Node struct {
+	// Addr is the node's RPC address, used to identify the node in incidents
+	Addr string
+	// Client is the RPC client connected to the node
+	Client rpcclient.Client
+}
+
+// Checker cross-checks responses of the primary target chain node with responses of other
+// configured target chain nodes. A response is accepted only if at least threshold nodes (including
+// the primary one) returned the same deterministic payload. Every disagreement is recorded as a
+// relay.QuorumIncident in the storage.
+type Checker struct {
+	nodes     []Node
+	threshold int
+	storage   relay.Storage
+	logger    *zap.Logger
+}
+
+// NewChecker constructs a new Checker. threshold is the number of nodes including the primary one
+// that have to agree on a response, zero threshold means that all the nodes have to agree.
+func NewChecker(nodes []Node, threshold int, storage relay.Storage, logger *zap.Logger) (*Checker, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes to cross-check responses with")
+	}
+
+	total := len(nodes) + 1
+	if threshold == 0 {
+		threshold = total
+	}
+	if threshold < 1 || threshold > total {
+		return nil, fmt.Errorf("quorum threshold must be in range [1; %d], got %d", total, threshold)
+	}
+
+	return &Checker{
+		nodes:     nodes,
+		threshold: threshold,
+		storage:   storage,
+		logger:    logger,
+	}, nil
+}
+
+// CheckABCIQuery makes sure the quorum agrees with the primary node's ABCI query response. The
+// value, the proof and the proof height are compared.
+func (c *Checker) CheckABCIQuery(ctx context.Context, path string, data []byte, opts rpcclient.ABCIQueryOptions, primary abci.ResponseQuery) error {
+	primaryPayload, err := abciQueryPayload(primary)
+	if err != nil {
+		return fmt.Errorf("failed to get primary node payload: %w", err)
+	}
+
+	request := fmt.Sprintf("path=%s data=%X height=%d", path, data, opts.Height)
+	return c.check(ctx, methodABCIQuery, request, primaryPayload, func(ctx context.Context, client rpcclient.Client) ([]byte, error) {
+		res, err := client.ABCIQueryWithOptions(ctx, path, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return abciQueryPayload(res.Response)
+	})
+}
+
+// CheckBlockResults makes sure the quorum agrees with the primary node's block results. The results
+// are compared by their merkle root hash, i.e. the same way the LastResultsHash of the next header
+// commits to them.
+func (c *Checker) CheckBlockResults(ctx context.Context, height int64, primary []*abci.ResponseDeliverTx) error {
+	request := fmt.Sprintf("height=%d", height)
+	return c.check(ctx, methodBlockResults, request, tmtypes.NewResults(primary).Hash(), func(ctx context.Context, client rpcclient.Client) ([]byte, error) {
+		res, err := client.BlockResults(ctx, &height)
+		if err != nil {
+			return nil, err
+		}
+		return tmtypes.NewResults(res.TxsResults).Hash(), nil
+	})
+}
+
+// check fetches the payloads from all the nodes concurrently and compares them with the primary
+// node's payload.
+func (c *Checker) check(
+	ctx context.Context,
+	method string,
+	request string,
+	primaryPayload []byte,
+	fetch func(ctx context.Context, client rpcclient.Client) ([]byte, error),
+) error {
+	start := time.Now()
+	responses := make([]relay.QuorumResponse, len(c.nodes))
+
+	wg := &sync.WaitGroup{}
+	for idx, node := range c.nodes {
+		wg.Add(1)
+		go func(idx int, node Node) {
+			defer wg.Done()
+
+			payload, err := fetch(ctx, node.Client)
+			responses[idx] = relay.QuorumResponse{Node: node.Addr, Payload: payload}
+			if err != nil {
+				responses[idx].Error = err.Error()
+				return
+			}
+			responses[idx].Agrees = bytes.Equal(payload, primaryPayload)
+		}(idx, node)
+	}
+	wg.Wait()
+
+	// the primary node always agrees with itself
+	agreed, diverged := 1, false
+	for _, response := range responses {
+		if response.Agrees {
+			agreed++
+		} else if response.Error == "" {
+			diverged = true
+		} else {
+			c.logger.Warn("failed to get response from quorum node", zap.String("method", method),
+				zap.String("node", response.Node), zap.String("error", response.Error))
+		}
+	}
+	accepted := agreed >= c.threshold
+	neutronmetrics.RecordActionDuration("QuorumCheck", time.Since(start).Seconds())
+
+	if diverged {
+		c.recordIncident(method, request, accepted, primaryPayload, responses)
+	}
+
+	if !accepted {
+		neutronmetrics.IncFailedQuorumCheck(method)
+		return fmt.Errorf("quorum is not reached for %s %s: %d of %d nodes agree, threshold is %d",
+			method, request, agreed, len(c.nodes)+1, c.threshold)
+	}
+	neutronmetrics.IncSuccessQuorumCheck(method)
+
+	return nil
+}
+
+func (c *Checker) recordIncident(method string, request string, accepted bool, primaryPayload []byte, responses []relay.QuorumResponse) {
+	neutronmetrics.IncQuorumIncidents(method)
+
+	incident := relay.QuorumIncident{
+		Method:    method,
+		Request:   request,
+		Time:      time.Now(),
+		Accepted:  accepted,
+		Responses: append([]relay.QuorumResponse{{Node: "primary", Payload: primaryPayload, Agrees: true}}, responses...),
+	}
+	c.logger.Error("target chain nodes disagree", zap.String("method", method), zap.String("request", request),
+		zap.Bool("accepted", accepted), zap.Any("responses", incident.Responses))
+
+	if err := c.storage.SaveQuorumIncident(&incident); err != nil {
+		c.logger.Error("failed to save quorum incident", zap.Error(err))
+	}
+}
+
+// abciQueryPayload returns the deterministic part of the ABCI query response. Fields like Log and
+// Info are node-specific and are not taken into account.
+func abciQueryPayload(res abci.ResponseQuery) ([]byte, error) {
+	deterministic := abci.ResponseQuery{
+		Code:      res.Code,
+		Key:       res.Key,
+		Value:     res.Value,
+		ProofOps:  res.ProofOps,
+		Height:    res.Height,
+		Codespace: res.Codespace,
+	}
+	return deterministic.Marshal()
+}
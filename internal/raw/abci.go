@@ -0,0 +1,48 @@
+package raw
+
+import (
+	"context"
+	"fmt"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+// ProtoMessage is a gRPC query request or response
+type ProtoMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+// ABCIError is returned by ABCIQuery if the query was handled by the node but failed
+type ABCIError struct {
+	Path string
+	Code uint32
+	Log  string
+}
+
+func (e ABCIError) Error() string {
+	return fmt.Sprintf("abci query %s failed with code=%d log=%s", e.Path, e.Code, e.Log)
+}
+
+// ABCIQuery makes the gRPC query at the path over the ABCI query of the RPC node
+func ABCIQuery(ctx context.Context, rpcClient rpcclient.Client, path string, request ProtoMessage, response ProtoMessage) error {
+	req, err := request.Marshal()
+	if err != nil {
+		return fmt.Errorf("error marshalling %s request: %w", path, err)
+	}
+
+	res, err := rpcClient.ABCIQueryWithOptions(ctx, path, req, rpcclient.DefaultABCIQueryOptions)
+	if err != nil {
+		return fmt.Errorf("error making abci query %s: %w", path, err)
+	}
+
+	if res.Response.Code != 0 {
+		return ABCIError{Path: path, Code: res.Response.Code, Log: res.Response.Log}
+	}
+
+	if err := response.Unmarshal(res.Response.Value); err != nil {
+		return fmt.Errorf("error unmarshalling %s response: %w", path, err)
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	authz "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	feegrant "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 )
 
 var (
@@ -15,6 +16,7 @@ var (
 		auth.AppModuleBasic{},
 		authz.AppModuleBasic{},
 		bank.AppModuleBasic{},
+		feegrant.AppModuleBasic{},
 	}
 )
 
@@ -2,7 +2,11 @@ package raw
 
 import (
 	"fmt"
+	"net/http"
 	neturl "net/url"
+	"time"
+
+	httptransport "github.com/go-openapi/runtime/client"
 
 	restclient "github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client"
 )
@@ -10,15 +14,14 @@ import (
 const restClientBasePath = "/"
 
 // NewRESTClient makes sure that the restAddr is formed correctly and returns a REST query.
-func NewRESTClient(restAddr string) (*restclient.HTTPAPIConsole, error) {
+func NewRESTClient(restAddr string, timeout time.Duration) (*restclient.HTTPAPIConsole, error) {
 	url, err := neturl.Parse(restAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse restAddr: %w", err)
 	}
 
-	return restclient.NewHTTPClientWithConfig(nil, &restclient.TransportConfig{
-		Host:     url.Host,
-		BasePath: restClientBasePath,
-		Schemes:  []string{url.Scheme},
-	}), nil
+	httpClient := &http.Client{Timeout: timeout}
+	transport := httptransport.NewWithClient(url.Host, restClientBasePath, []string{url.Scheme}, httpClient)
+
+	return restclient.New(transport, nil), nil
 }
@@ -0,0 +1,22 @@
+package relay
+
+import "time"
+
+// TargetChainHealth is the state of the target chain as seen by the relayer's target node
+type TargetChainHealth struct {
+	// Healthy is false if the target chain has halted or the target node lags behind, the queries are
+	// not processed then
+	Healthy bool `json:"healthy"`
+	// Reason explains why the target chain is unhealthy
+	Reason          string    `json:"reason,omitempty"`
+	LatestHeight    uint64    `json:"latest_height"`
+	LatestBlockTime time.Time `json:"latest_block_time"`
+	// PeersHeight is the highest height reported by the other target nodes, 0 if there are none
+	PeersHeight uint64    `json:"peers_height"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// ChainHealthProvider tells the current health of the target chain
+type ChainHealthProvider interface {
+	TargetChainHealth() TargetChainHealth
+}
@@ -0,0 +1,34 @@
+package relay
+
+import "fmt"
+
+// ErrorClass tells how to handle a failed query result submission
+type ErrorClass string
+
+const (
+	// ErrorClassUnknown means the error is not classified, the caller decides how to handle it
+	ErrorClassUnknown ErrorClass = ""
+	// ErrorClassRetryable errors are transient, the submission is not stored and is retried later
+	ErrorClassRetryable ErrorClass = "retryable"
+	// ErrorClassIgnorable errors are stored as unsuccessful submissions which can be resubmitted manually
+	ErrorClassIgnorable ErrorClass = "ignorable"
+	// ErrorClassCritical errors stop the relayer
+	ErrorClassCritical ErrorClass = "critical"
+)
+
+// ErrorClassifier classifies the errors of the query result submissions
+type ErrorClassifier interface {
+	Classify(err error) ErrorClass
+}
+
+// TxError is an error of a transaction rejected by the Neutron chain
+type TxError struct {
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+// Error implements the error interface.
+func (e *TxError) Error() string {
+	return fmt.Sprintf("codespace=%s code=%d log=%s", e.Codespace, e.Code, e.Log)
+}
@@ -0,0 +1,8 @@
+package relay
+
+// GasObserver learns the gas consumption of the submitted transactions from their execution results
+type GasObserver interface {
+	// ObserveTxResult reports the gas used by the committed transaction with the neutronHash, outOfGas is true
+	// if the transaction failed because it ran out of gas
+	ObserveTxResult(neutronHash string, gasUsed int64, outOfGas bool)
+}
@@ -0,0 +1,15 @@
+package relay
+
+import "context"
+
+// GrantsProvider knows on behalf of whom the query results are submitted and who pays for it. It allows
+// contract owners to sponsor their own queries with fee grants, and the relayer to submit results
+// on behalf of an authz granter.
+type GrantsProvider interface {
+	// FeeGranter returns the address of the fee granter paying for the query result submission,
+	// or an empty string if the relayer pays itself.
+	FeeGranter(ctx context.Context, queryID uint64) (string, error)
+	// AuthzGranter returns the address MsgSubmitQueryResult is executed on behalf of with MsgExec,
+	// or an empty string if the relayer submits results itself.
+	AuthzGranter() string
+}
@@ -0,0 +1,19 @@
+package relay
+
+import "time"
+
+// HarvestedDeposit is an audit record of the deposit collected by removing a timed out query
+type HarvestedDeposit struct {
+	// QueryID is the id of the removed query
+	QueryID uint64 `json:"query_id"`
+	// Owner is the address that registered the query
+	Owner string `json:"owner"`
+	// Deposit is the collected deposit, e.g. 1000000untrn
+	Deposit string `json:"deposit"`
+	// NeutronHash is the hash of the neutron transaction which removed the query
+	NeutronHash string `json:"neutron_hash"`
+	// Height is the neutron height the query was removed at
+	Height int64 `json:"height"`
+	// Time is the time when the deposit was recorded
+	Time time.Time `json:"time"`
+}
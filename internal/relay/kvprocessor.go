@@ -7,5 +7,25 @@ type KVProcessor interface {
 	// ProcessAndSubmit handles an incoming KV interchain query message. It checks whether it's time
 	// to execute the query (based on the relayer's settings), queries values and proofs for the query
 	// keys, and submits the result to the Neutron chain.
-	ProcessAndSubmit(ctx context.Context, m *MessageKV) error
+	// Successfully submitted results are sent to submittedTxsTasksQueue to get their commit status checked.
+	ProcessAndSubmit(ctx context.Context, m *MessageKV, submittedTxsTasksQueue chan PendingSubmittedTxInfo) error
+	// Rebroadcast submits fresh values and proofs for a KV query whose last submission hasn't been committed
+	// or has failed. The update period is not checked since it counts from the submission being replaced.
+	Rebroadcast(ctx context.Context, m *MessageKV, submittedTxsTasksQueue chan PendingSubmittedTxInfo) error
+}
+
+// NewErrSubmitKVProofCritical creates a new ErrSubmitKVProofCritical.
+func NewErrSubmitKVProofCritical(details error) ErrSubmitKVProofCritical {
+	return ErrSubmitKVProofCritical{details: details}
+}
+
+// ErrSubmitKVProofCritical is an error type that represents errors critical for the Relayer.
+type ErrSubmitKVProofCritical struct {
+	// details is the inner error.
+	details error
+}
+
+// Error implements the error interface.
+func (e ErrSubmitKVProofCritical) Error() string {
+	return "failed to submit kv proof: " + e.details.Error()
 }
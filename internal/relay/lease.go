@@ -0,0 +1,29 @@
+package relay
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// RoleLeader is the role of the relayer instance holding the lease, only the leader submits query results
+	RoleLeader = "leader"
+	// RoleStandby is the role of the relayer instance waiting to take the lease over
+	RoleStandby = "standby"
+)
+
+// Lease is a lease the relayer instances compete for in the active-passive mode, the instance holding
+// the lease is the leader
+type Lease interface {
+	// TryAcquire acquires the lease for the holder or renews it if the holder already has it. It returns
+	// false if the lease is held by another holder which has renewed it within the ttl.
+	TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// Release releases the lease if it's held by the holder, so a standby can take it over right away
+	Release(ctx context.Context, holder string) error
+}
+
+// RoleProvider tells the current role of the relayer instance
+type RoleProvider interface {
+	// Role returns either RoleLeader or RoleStandby
+	Role() string
+}
@@ -0,0 +1,19 @@
+package relay
+
+import (
+	"context"
+
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+)
+
+// NeutronQuerier queries the interchain queries and the IBC connections state of Neutron
+type NeutronQuerier interface {
+	// RegisteredQuery returns the registered query with the queryID
+	RegisteredQuery(ctx context.Context, queryID uint64) (*neutrontypes.RegisteredQuery, error)
+	// RegisteredQueries returns all the registered queries of the owners on the connection, empty
+	// filters match any query
+	RegisteredQueries(ctx context.Context, owners []string, connectionID string) ([]neutrontypes.RegisteredQuery, error)
+	// Connection returns the IBC connection with the connectionID
+	Connection(ctx context.Context, connectionID string) (*connectiontypes.ConnectionEnd, error)
+}
@@ -0,0 +1,18 @@
+package relay
+
+import (
+	"github.com/cosmos/ibc-go/v4/modules/core/exported"
+
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+)
+
+// ProofVerifier checks proofs obtained from the target chain against trusted headers before they
+// are submitted to the Neutron chain. It allows to reject results of a faulty or malicious target
+// node locally instead of paying for a failed MsgSubmitQueryResult.
+type ProofVerifier interface {
+	// VerifyKVProofs verifies the KV values proofs against the AppHash of the header.
+	VerifyKVProofs(header exported.Header, values []*neutrontypes.StorageValue) error
+	// VerifyTxProof verifies the tx inclusion proof against the header's DataHash and the tx delivery
+	// proof against the nextHeader's LastResultsHash.
+	VerifyTxProof(header exported.Header, nextHeader exported.Header, tx *neutrontypes.TxValue) error
+}
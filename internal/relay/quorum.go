@@ -0,0 +1,29 @@
+package relay
+
+import "time"
+
+// QuorumIncident describes a disagreement between target chain nodes on the same request
+type QuorumIncident struct {
+	// Method is the name of the cross-checked RPC method, e.g. abci_query or block_results
+	Method string `json:"method"`
+	// Request is the human-readable description of the cross-checked request
+	Request string `json:"request"`
+	// Time is the time when the incident was recorded
+	Time time.Time `json:"time"`
+	// Accepted is true if the primary node response was nevertheless accepted by the quorum
+	Accepted bool `json:"accepted"`
+	// Responses contains the responses of all the nodes participated in the cross-check
+	Responses []QuorumResponse `json:"responses"`
+}
+
+// QuorumResponse is a response of a single target chain node in a quorum cross-check
+type QuorumResponse struct {
+	// Node is the RPC address of the node
+	Node string `json:"node"`
+	// Payload is the deterministic part of the node's response the nodes are compared by
+	Payload []byte `json:"payload,omitempty"`
+	// Error is the error returned by the node if any
+	Error string `json:"error,omitempty"`
+	// Agrees is true if the node's payload matches the primary node's payload
+	Agrees bool `json:"agrees"`
+}
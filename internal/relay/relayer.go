@@ -36,6 +36,8 @@ type Relayer struct {
 	txProcessor TXProcessor
 	kvProcessor KVProcessor
 	targetChain *relayer.Chain
+	// chainHealth pauses the queries processing while the target chain is unhealthy, nil disables the pause
+	chainHealth ChainHealthProvider
 }
 
 func NewRelayer(
@@ -45,6 +47,7 @@ func NewRelayer(
 	txProcessor TXProcessor,
 	kvProcessor KVProcessor,
 	targetChain *relayer.Chain,
+	chainHealth ChainHealthProvider,
 	logger *zap.Logger,
 ) *Relayer {
 	return &Relayer{
@@ -55,6 +58,7 @@ func NewRelayer(
 		txProcessor: txProcessor,
 		kvProcessor: kvProcessor,
 		targetChain: targetChain,
+		chainHealth: chainHealth,
 	}
 }
 
@@ -65,20 +69,81 @@ func (r *Relayer) Run(
 	ctx context.Context,
 	queriesTasksQueue <-chan neutrontypes.RegisteredQuery, // Input tasks come from this channel
 	submittedTxsTasksQueue chan PendingSubmittedTxInfo, // Tasks for the TxSubmitChecker are sent to this channel
+	tasksFeedbackQueue chan<- TaskFeedback, // Results of the input tasks processing are sent to this channel
+	deposed <-chan struct{}, // Closed once the instance loses the leadership, nil if it can't be lost
 ) error {
+	// processCtx outlives the ctx by the drain timeout, so the task in progress on shutdown is finished
+	// instead of being cut off. The queued tasks are left to be rerun after the restart. On the leadership
+	// loss the task in progress is cancelled right away, since another instance is taking over the submissions.
+	processCtx, cancelProcessing := context.WithCancel(context.Background())
+	defer cancelProcessing()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-deposed:
+			r.logger.Warn("leadership lost, cancelling task in progress")
+			cancelProcessing()
+			return
+		case <-processCtx.Done():
+			return
+		}
+		timer := time.NewTimer(r.cfg.ShutdownDrainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			r.logger.Warn("task in progress has not been drained in time, cancelling it")
+			cancelProcessing()
+		case <-deposed:
+			r.logger.Warn("leadership lost, cancelling task in progress")
+			cancelProcessing()
+		case <-processCtx.Done():
+		}
+	}()
+
 	for {
+		// the shutdown and the leadership loss take precedence over the queued tasks
+		if ctx.Err() != nil {
+			r.logger.Info("context cancelled, shutting down relayer...")
+			return nil
+		}
+		select {
+		case <-deposed:
+			r.logger.Info("leadership lost, shutting down relayer...")
+			return nil
+		default:
+		}
+
 		var err error
 		select {
 		case query := <-queriesTasksQueue:
 			start := time.Now()
 			neutronmetrics.SetSubscriberTaskQueueNumElements(len(queriesTasksQueue))
+			if r.chainHealth != nil {
+				// the paused task is retried by the subscriber, so the query is processed again after the target
+				// chain recovers
+				if health := r.chainHealth.TargetChainHealth(); !health.Healthy {
+					r.logger.Debug("target chain is unhealthy, skipping query", zap.Uint64("query_id", query.Id),
+						zap.String("reason", health.Reason))
+					neutronmetrics.IncPausedRequest(query.QueryType)
+					r.sendTaskFeedback(tasksFeedbackQueue, TaskFeedback{QueryID: query.Id, Paused: true})
+					continue
+				}
+			}
+			if err := r.storage.SetQueryTaskStatus(query.Id, TaskInFlight); err != nil {
+				r.logger.Error("failed to set query task status", zap.Uint64("query_id", query.Id), zap.Error(err))
+			}
 			switch query.QueryType {
 			case string(neutrontypes.InterchainQueryTypeKV):
 				msg := &MessageKV{QueryId: query.Id, KVKeys: query.Keys}
-				err = r.processMessageKV(ctx, msg)
+				err = r.processMessageKV(processCtx, msg, submittedTxsTasksQueue)
+
+				var critErr ErrSubmitKVProofCritical
+				if errors.As(err, &critErr) {
+					return err
+				}
 			case string(neutrontypes.InterchainQueryTypeTX):
 				msg := &MessageTX{QueryId: query.Id, TransactionsFilter: query.TransactionsFilter}
-				err = r.processMessageTX(ctx, msg, submittedTxsTasksQueue)
+				err = r.processMessageTX(processCtx, msg, submittedTxsTasksQueue)
 
 				var critErr ErrSubmitTxProofCritical
 				if errors.As(errors.Unwrap(err), &critErr) {
@@ -94,6 +159,7 @@ func (r *Relayer) Run(
 			} else {
 				neutronmetrics.AddSuccessRequest(string(query.QueryType), time.Since(start).Seconds())
 			}
+			r.sendTaskFeedback(tasksFeedbackQueue, TaskFeedback{QueryID: query.Id, Success: err == nil})
 		case <-ctx.Done():
 			r.logger.Info("context cancelled, shutting down relayer...")
 			return nil
@@ -101,10 +167,22 @@ func (r *Relayer) Run(
 	}
 }
 
+// sendTaskFeedback sends the task processing result back to the subscriber. It never blocks the
+// relayer: if the feedback queue is full, the feedback is dropped, and the subscriber eventually
+// catches up on the query state with its periodic reconciliation.
+func (r *Relayer) sendTaskFeedback(tasksFeedbackQueue chan<- TaskFeedback, feedback TaskFeedback) {
+	select {
+	case tasksFeedbackQueue <- feedback:
+	default:
+		r.logger.Warn("tasks feedback queue is full, dropping feedback",
+			zap.Uint64("query_id", feedback.QueryID), zap.Bool("success", feedback.Success))
+	}
+}
+
 // processMessageKV handles an incoming KV interchain query message and passes it to the kvProcessor for further processing.
-func (r *Relayer) processMessageKV(ctx context.Context, m *MessageKV) error {
+func (r *Relayer) processMessageKV(ctx context.Context, m *MessageKV, submittedTxsTasksQueue chan PendingSubmittedTxInfo) error {
 	r.logger.Debug("running processMessageKV for msg", zap.Uint64("query_id", m.QueryId))
-	return r.kvProcessor.ProcessAndSubmit(ctx, m)
+	return r.kvProcessor.ProcessAndSubmit(ctx, m, submittedTxsTasksQueue)
 }
 
 // processMessageTX handles an incoming TX interchain query message. It fetches proven transactions
@@ -2,6 +2,8 @@ package relay
 
 import (
 	"time"
+
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
 
 // PendingSubmittedTxInfo contains information about transaction which was submitted but has to be confirmed (committed or not)
@@ -12,6 +14,16 @@ type PendingSubmittedTxInfo struct {
 	SubmittedTxHash string `json:"submitted_tx_hash"`
 	// NeutronHash is the hash of the *neutron chain transaction* which is responsible for delivering remote transaction to neutron
 	NeutronHash string `json:"neutron_hash"`
+	// QueryType is the type of the query the transaction was submitted for. Empty value means TX query type
+	QueryType string `json:"query_type,omitempty"`
+	// SubmittedHeight is the neutron height the submission was first broadcast at, it's kept across rebroadcasts.
+	// Zero value means the height is not known yet
+	SubmittedHeight uint64 `json:"submitted_height,omitempty"`
+}
+
+// IsKV returns true if the transaction was submitted for a KV query
+func (i PendingSubmittedTxInfo) IsKV() bool {
+	return neutrontypes.InterchainQueryType(i.QueryType).IsKV()
 }
 
 type UnsuccessfulTxInfo struct {
@@ -29,6 +41,20 @@ type UnsuccessfulTxInfo struct {
 	Message string `json:"message"`
 }
 
+// UnsuccessfulKVInfo describes the last unsuccessful KV query result submission
+type UnsuccessfulKVInfo struct {
+	// QueryID is the query_id the result was submitted for
+	QueryID uint64 `json:"query_id"`
+	// NeutronHash is the hash of the *neutron chain transaction* which is responsible for delivering the result to neutron
+	NeutronHash string `json:"neutron_hash"`
+	// ErrorTime is the time when the error was added
+	ErrorTime time.Time `json:"error_time"`
+	// Status is the status of unsuccessful submission
+	Status SubmittedTxStatus `json:"status"`
+	// Message is the more descriptive message for the error
+	Message string `json:"message"`
+}
+
 // SubmittedTxInfo is a struct which contains status of fetched and submitted transaction
 type SubmittedTxInfo struct {
 	// SubmittedTxStatus is a status of a processing state
@@ -48,8 +74,36 @@ const (
 	Committed SubmittedTxStatus = "Committed"
 	// ErrorOnCommit describes error during commit operation
 	ErrorOnCommit SubmittedTxStatus = "ErrorOnCommit"
+	// Expired describes tx which was neither committed nor rebroadcast successfully within the confirmation deadline
+	Expired SubmittedTxStatus = "Expired"
 )
 
+// QueryTaskStatus is the processing state of the latest task of a query
+type QueryTaskStatus string
+
+const (
+	// TaskQueued describes a task sent to the relayer but not yet picked up
+	TaskQueued QueryTaskStatus = "Queued"
+	// TaskInFlight describes a task being processed by the relayer
+	TaskInFlight QueryTaskStatus = "InFlight"
+	// TaskDone describes a successfully processed task
+	TaskDone QueryTaskStatus = "Done"
+	// TaskFailed describes a failed task waiting for a retry
+	TaskFailed QueryTaskStatus = "Failed"
+)
+
+// QueryTask is the persisted state of the latest task of a query, it lets the subscriber restore the query
+// schedule after a restart: the queued and in-flight tasks are interrupted and rerun right away
+type QueryTask struct {
+	QueryID uint64          `json:"query_id"`
+	Status  QueryTaskStatus `json:"status"`
+	// ScheduledHeight is the Neutron height the task was scheduled at
+	ScheduledHeight uint64 `json:"scheduled_height"`
+	// Failures is the number of consecutive failures of the query tasks
+	Failures  uint64    `json:"failures"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // Storage is local storage we use to store queries history: known queries, know transactions and its statuses
 type Storage interface {
 	GetAllPendingTxs() ([]*PendingSubmittedTxInfo, error)
@@ -58,6 +112,19 @@ type Storage interface {
 	GetLastQueryHeight(queryID uint64) (block uint64, found bool, err error)
 	SetLastQueryHeight(queryID uint64, block uint64) error
 	SetTxStatus(queryID uint64, hash string, neutronHash string, status SubmittedTxInfo, processedTx *Transaction) (err error)
+	GetAllUnsuccessfulKVs() ([]*UnsuccessfulKVInfo, error)
+	GetCachedKV(queryID uint64) (*MessageKV, error)
+	SetKVStatus(queryID uint64, neutronHash string, status SubmittedTxInfo, processedKV *MessageKV) (err error)
 	TxExists(queryID uint64, hash string) (exists bool, err error)
+	RemovePendingTx(neutronHash string) error
+	UpdatePendingTx(txInfo PendingSubmittedTxInfo) error
+	SaveQuorumIncident(incident *QuorumIncident) error
+	GetAllQuorumIncidents() ([]*QuorumIncident, error)
+	SaveHarvestedDeposit(deposit *HarvestedDeposit) error
+	GetAllHarvestedDeposits() ([]*HarvestedDeposit, error)
+	SetQueryTask(task *QueryTask) error
+	SetQueryTaskStatus(queryID uint64, status QueryTaskStatus) error
+	RemoveQueryTask(queryID uint64) error
+	GetAllQueryTasks() ([]*QueryTask, error)
 	Close() error
 }
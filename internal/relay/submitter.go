@@ -10,6 +10,11 @@ import (
 
 // Submitter knows how to submit proof to the chain
 type Submitter interface {
-	SubmitKVProof(ctx context.Context, height, revision, queryId uint64, proof []*neutrontypes.StorageValue, updateClientMsg sdk.Msg) error
+	SubmitKVProof(ctx context.Context, height, revision, queryId uint64, proof []*neutrontypes.StorageValue, updateClientMsg sdk.Msg) (string, error)
 	SubmitTxProof(ctx context.Context, queryId uint64, proof *neutrontypes.Block) (string, error)
 }
+
+// TxSender signs the transactions with the relayer's key and broadcasts them, it returns the neutron tx hash
+type TxSender interface {
+	Send(ctx context.Context, msgs []sdk.Msg, feeGranter sdk.AccAddress) (string, error)
+}
@@ -10,8 +10,20 @@ import (
 // Subscriber is an interface that subscribes to Neutron and provides chain data in real time.
 type Subscriber interface {
 	// Subscribe starts sending neutrontypes.RegisteredQuery values to the tasks channel when
-	// respective queries need to be updated.
-	Subscribe(ctx context.Context, tasks chan neutrontypes.RegisteredQuery) error
+	// respective queries need to be updated. Results of the tasks processing are read from the
+	// tasksFeedback channel to reschedule failed tasks.
+	Subscribe(ctx context.Context, tasks chan neutrontypes.RegisteredQuery, tasksFeedback <-chan TaskFeedback) error
+}
+
+// TaskFeedback is the result of a task processing sent back from the Relayer to the Subscriber.
+type TaskFeedback struct {
+	// QueryID is the ID of the processed query.
+	QueryID uint64
+	// Success is true if the task has been processed successfully.
+	Success bool
+	// Paused is true if the task has been skipped since the processing is paused. The task is retried
+	// without backing off, as it hasn't failed.
+	Paused bool
 }
 
 // MessageKV contains params of a KV interchain query.
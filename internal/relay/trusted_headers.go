@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/cosmos/ibc-go/v4/modules/core/exported"
+	"github.com/cosmos/relayer/v2/relayer"
 )
 
 // TrustedHeaderFetcher able to get trusted headers for a given height
@@ -11,3 +12,10 @@ type TrustedHeaderFetcher interface {
 	// Fetch returns only one trusted Header for specified height
 	Fetch(ctx context.Context, height uint64) (exported.Header, error)
 }
+
+// TargetRevisionTracker keeps track of the target chain revisions, i.e. the chain IDs the target chain has
+// had since the relayer start
+type TargetRevisionTracker interface {
+	// AddRevision makes the chain serve the heights starting from the startHeight
+	AddRevision(chain *relayer.Chain, startHeight uint64)
+}
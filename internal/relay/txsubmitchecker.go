@@ -2,7 +2,8 @@ package relay
 
 import "context"
 
-// TxSubmitChecker runs in background and updates submitted tx statuses
+// TxSubmitChecker runs in background and updates submitted tx statuses. The evicted txs are rebroadcast,
+// and the rebroadcast ones are sent to submittedTxsTasksQueue.
 type TxSubmitChecker interface {
-	Run(ctx context.Context, submittedTxsTasksQueue <-chan PendingSubmittedTxInfo) error
+	Run(ctx context.Context, submittedTxsTasksQueue chan PendingSubmittedTxInfo) error
 }
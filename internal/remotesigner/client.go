@@ -0,0 +1,120 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// Client is a Signer which signs transactions with a key held by the remote signer server
+type Client struct {
+	host      *url.URL
+	authToken string
+	client    http.Client
+}
+
+// NewClient returns a Client of the remote signer server at host, e.g. https://signer.host:9998. The authToken
+// is sent as a bearer token if not empty, the tlsConfig is used for the https hosts if not nil.
+func NewClient(host string, timeout time.Duration, authToken string, tlsConfig *tls.Config) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("host parsing error: %w", err)
+	}
+
+	u.Path = ""
+	u.RawQuery = ""
+	client := http.Client{
+		Timeout: timeout,
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return &Client{
+		host:      u,
+		authToken: authToken,
+		client:    client,
+	}, nil
+}
+
+func (c *Client) PubKey(ctx context.Context) (cryptotypes.PubKey, error) {
+	var res PubKeyResponse
+	if err := c.do(ctx, http.MethodGet, PubKeyResource, nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to get public key from remote signer: %w", err)
+	}
+
+	return decodePubKey(res.PubKey)
+}
+
+func (c *Client) Sign(ctx context.Context, signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	var res SignResponse
+	if err := c.do(ctx, http.MethodPost, SignResource, SignRequest{SignBytes: signBytes}, &res); err != nil {
+		return nil, nil, fmt.Errorf("failed to sign with remote signer: %w", err)
+	}
+
+	pubKey, err := decodePubKey(res.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res.Signature, pubKey, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, resource string, reqBody interface{}, resBody interface{}) error {
+	u := *c.host
+	u.Path = resource
+
+	var body io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to build http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.authToken != "" {
+		req.Header.Set("Authorization", bearerPrefix+c.authToken)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("got unexpected http response status code: %d, body: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(resBody); err != nil {
+		return fmt.Errorf("could not decode http response: %w", err)
+	}
+
+	return nil
+}
+
+func decodePubKey(bz []byte) (cryptotypes.PubKey, error) {
+	if len(bz) != secp256k1.PubKeySize {
+		return nil, fmt.Errorf("invalid secp256k1 public key length %d", len(bz))
+	}
+
+	return &secp256k1.PubKey{Key: bz}, nil
+}
@@ -0,0 +1,28 @@
+package remotesigner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/submit"
+)
+
+// NewPubKeyKeybase returns an in-memory keyring holding only the public key of the signer under keyName.
+// The neutron chain provider builds messages (e.g. MsgUpdateClient) with the address of its key, so
+// it needs a keyring even if it never signs anything.
+func NewPubKeyKeybase(ctx context.Context, signer submit.Signer, keyName string) (keyring.Keyring, error) {
+	pubKey, err := signer.PubKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer public key: %w", err)
+	}
+
+	keybase := keyring.NewInMemory()
+	if _, err := keybase.SavePubKey(keyName, pubKey, hd.Secp256k1Type); err != nil {
+		return nil, fmt.Errorf("failed to save signer public key: %w", err)
+	}
+
+	return keybase, nil
+}
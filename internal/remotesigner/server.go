@@ -0,0 +1,276 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	"github.com/gorilla/mux"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	"go.uber.org/zap"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/submit"
+)
+
+const ServerContext = "remote_signer"
+
+var msgExecTypeURL = sdk.MsgTypeURL(&authz.MsgExec{})
+
+// DefaultAllowedMsgTypes are the messages the relayer sends to Neutron: the query results, the client updates
+// and the removals of the expired queries by the janitor
+var DefaultAllowedMsgTypes = []string{
+	sdk.MsgTypeURL(&neutrontypes.MsgSubmitQueryResult{}),
+	sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}),
+	sdk.MsgTypeURL(&neutrontypes.MsgRemoveInterchainQueryRequest{}),
+}
+
+// Server is a reference remote signer. It signs only SIGN_MODE_DIRECT transactions for the configured chain
+// which contain nothing but the allowed messages.
+type Server struct {
+	signer          submit.Signer
+	chainID         string
+	allowedMsgTypes map[string]struct{}
+	// maxFee maps the denoms the fee can be paid in to the maximal amounts, nil if the fee is not checked
+	maxFee map[string]sdk.Int
+	// authToken is the bearer token the clients have to present, empty disables the check
+	authToken string
+	logger    *zap.Logger
+}
+
+// NewServer returns a Server signing with the signer. An empty maxFee disables the fee check, otherwise
+// the fee can be paid only in its denoms up to its amounts.
+func NewServer(
+	signer submit.Signer,
+	chainID string,
+	allowedMsgTypes []string,
+	maxFee sdk.Coins,
+	authToken string,
+	logger *zap.Logger,
+) *Server {
+	if len(allowedMsgTypes) == 0 {
+		allowedMsgTypes = DefaultAllowedMsgTypes
+	}
+
+	allowed := make(map[string]struct{}, len(allowedMsgTypes))
+	for _, msgType := range allowedMsgTypes {
+		allowed[msgType] = struct{}{}
+	}
+
+	var maxFeeByDenom map[string]sdk.Int
+	if !maxFee.Empty() {
+		maxFeeByDenom = make(map[string]sdk.Int, len(maxFee))
+		for _, coin := range maxFee {
+			maxFeeByDenom[coin.Denom] = coin.Amount
+		}
+	}
+
+	return &Server{
+		signer:          signer,
+		chainID:         chainID,
+		allowedMsgTypes: allowed,
+		maxFee:          maxFeeByDenom,
+		authToken:       authToken,
+		logger:          logger,
+	}
+}
+
+// Run serves the remote signer api on listenAddr until the ctx is done, over TLS if tlsConfig is not nil
+func (s *Server) Run(ctx context.Context, listenAddr string, tlsConfig *tls.Config) error {
+	server := &http.Server{
+		Addr:      listenAddr,
+		Handler:   s.Router(),
+		TLSConfig: tlsConfig,
+	}
+	errch := make(chan error)
+
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			// the certificates are already in the tlsConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
+			if err != http.ErrServerClosed {
+				s.logger.Error("failed to serve http", zap.Error(err))
+				errch <- err
+			}
+		}
+	}()
+
+	select {
+	case err := <-errch:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("shutting down the remote signer http")
+	shutdownCtx, cancelShutdownCtx := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelShutdownCtx()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		s.logger.Error("failed to shutdown remote signer http gracefully", zap.Error(err))
+		return nil
+	}
+
+	s.logger.Info("remote signer http shut down successfully")
+	return nil
+}
+
+func (s *Server) Router() *mux.Router {
+	router := mux.NewRouter().StrictSlash(true)
+	router.Use(s.authenticate)
+	router.HandleFunc(PubKeyResource, s.pubKey).Methods(http.MethodGet)
+	router.HandleFunc(SignResource, s.sign).Methods(http.MethodPost)
+	return router
+}
+
+// authenticate rejects the requests without the bearer token if the server has one
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.authToken != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), bearerPrefix)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
+				s.logger.Warn("refused unauthorized request", zap.String("remote_addr", r.RemoteAddr))
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) pubKey(w http.ResponseWriter, r *http.Request) {
+	pubKey, err := s.signer.PubKey(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get public key", zap.Error(err))
+		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		return
+	}
+
+	s.respond(w, PubKeyResponse{PubKey: pubKey.Bytes()})
+}
+
+func (s *Server) sign(w http.ResponseWriter, r *http.Request) {
+	reqBody := SignRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		s.logger.Error("failed to decode request body of sign", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Error processing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.checkSignBytes(reqBody.SignBytes); err != nil {
+		s.logger.Warn("refused to sign", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Refused to sign: %s", err), http.StatusForbidden)
+		return
+	}
+
+	signature, pubKey, err := s.signer.Sign(r.Context(), reqBody.SignBytes)
+	if err != nil {
+		s.logger.Error("failed to sign", zap.Error(err))
+		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		return
+	}
+
+	s.respond(w, SignResponse{Signature: signature, PubKey: pubKey.Bytes()})
+}
+
+// checkSignBytes makes sure the sign bytes are a SignDoc for the expected chain with allowed messages only
+// and the fee within the cap
+func (s *Server) checkSignBytes(signBytes []byte) error {
+	var signDoc txtypes.SignDoc
+	if err := signDoc.Unmarshal(signBytes); err != nil {
+		return fmt.Errorf("failed to unmarshal SignDoc: %w", err)
+	}
+
+	if signDoc.ChainId != s.chainID {
+		return fmt.Errorf("unexpected chain id %s", signDoc.ChainId)
+	}
+
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(signDoc.AuthInfoBytes); err != nil {
+		return fmt.Errorf("failed to unmarshal AuthInfo: %w", err)
+	}
+	if err := s.checkFee(authInfo.Fee); err != nil {
+		return err
+	}
+
+	var body txtypes.TxBody
+	if err := body.Unmarshal(signDoc.BodyBytes); err != nil {
+		return fmt.Errorf("failed to unmarshal TxBody: %w", err)
+	}
+
+	if len(body.Messages) == 0 {
+		return fmt.Errorf("transaction has no messages")
+	}
+	if len(body.ExtensionOptions) != 0 || len(body.NonCriticalExtensionOptions) != 0 {
+		return fmt.Errorf("transaction extension options are not allowed")
+	}
+
+	for _, msg := range body.Messages {
+		// MsgExec is allowed if it executes allowed messages only
+		if msg.TypeUrl == msgExecTypeURL {
+			var execMsg authz.MsgExec
+			if err := execMsg.Unmarshal(msg.Value); err != nil {
+				return fmt.Errorf("failed to unmarshal MsgExec: %w", err)
+			}
+			for _, innerMsg := range execMsg.Msgs {
+				if err := s.checkMsgType(innerMsg.TypeUrl); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := s.checkMsgType(msg.TypeUrl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkFee makes sure the fee is paid in the allowed denoms and doesn't exceed the cap
+func (s *Server) checkFee(fee *txtypes.Fee) error {
+	if s.maxFee == nil || fee == nil {
+		return nil
+	}
+
+	for _, coin := range fee.Amount {
+		maxAmount, ok := s.maxFee[coin.Denom]
+		if !ok {
+			return fmt.Errorf("fee denom %s is not allowed", coin.Denom)
+		}
+		if coin.Amount.GT(maxAmount) {
+			return fmt.Errorf("fee %s exceeds the cap %s%s", coin, maxAmount, coin.Denom)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) checkMsgType(typeURL string) error {
+	if _, ok := s.allowedMsgTypes[typeURL]; !ok {
+		return fmt.Errorf("message type %s is not allowed", typeURL)
+	}
+
+	return nil
+}
+
+func (s *Server) respond(w http.ResponseWriter, res interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Error("failed to encode response", zap.Error(err))
+		http.Error(w, "Error processing request", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,260 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	"go.uber.org/zap"
+)
+
+const testChainID = "neutron-test-1"
+
+type privKeySigner struct {
+	privKey cryptotypes.PrivKey
+}
+
+func (s privKeySigner) PubKey(_ context.Context) (cryptotypes.PubKey, error) {
+	return s.privKey.PubKey(), nil
+}
+
+func (s privKeySigner) Sign(_ context.Context, signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	signature, err := s.privKey.Sign(signBytes)
+	return signature, s.privKey.PubKey(), err
+}
+
+func signBytes(t *testing.T, msg sdk.Msg) []byte {
+	t.Helper()
+
+	return signBytesWithFee(t, msg, sdk.NewCoins(sdk.NewInt64Coin("untrn", 1000)))
+}
+
+func signBytesWithFee(t *testing.T, msg sdk.Msg, fee sdk.Coins) []byte {
+	t.Helper()
+
+	anyMsg, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		t.Fatalf("failed to pack message: %v", err)
+	}
+	bodyBytes, err := (&txtypes.TxBody{Messages: []*codectypes.Any{anyMsg}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal tx body: %v", err)
+	}
+	authInfoBytes, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{Amount: fee, GasLimit: 200000}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal auth info: %v", err)
+	}
+	bz, err := (&txtypes.SignDoc{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, ChainId: testChainID}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal sign doc: %v", err)
+	}
+	return bz
+}
+
+func TestServerAuthToken(t *testing.T) {
+	ctx := context.Background()
+	privKey := secp256k1.GenPrivKey()
+	server := NewServer(privKeySigner{privKey: privKey}, testChainID, nil, nil, "secret", zap.NewNop())
+	ts := httptest.NewServer(server.Router())
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, time.Second, "secret", nil)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	pubKey, err := client.PubKey(ctx)
+	if err != nil {
+		t.Fatalf("failed to get public key: %v", err)
+	}
+	if !pubKey.Equals(privKey.PubKey()) {
+		t.Fatalf("unexpected public key %s", pubKey)
+	}
+
+	bz := signBytes(t, &clienttypes.MsgUpdateClient{Signer: "neutron1relayer"})
+	signature, pubKey, err := client.Sign(ctx, bz)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if !pubKey.VerifySignature(bz, signature) {
+		t.Fatalf("invalid signature")
+	}
+
+	if _, _, err := client.Sign(ctx, signBytes(t, &banktypes.MsgSend{})); err == nil {
+		t.Fatalf("expected the signer to refuse the not allowed message")
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		unauthorized, err := NewClient(ts.URL, time.Second, token, nil)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		if _, err := unauthorized.PubKey(ctx); err == nil {
+			t.Fatalf("expected the request with token %q to be refused", token)
+		}
+		if _, _, err := unauthorized.Sign(ctx, bz); err == nil {
+			t.Fatalf("expected the sign request with token %q to be refused", token)
+		}
+	}
+}
+
+func TestServerMaxFee(t *testing.T) {
+	ctx := context.Background()
+	privKey := secp256k1.GenPrivKey()
+	maxFee := sdk.NewCoins(sdk.NewInt64Coin("untrn", 5000))
+	ts := httptest.NewServer(NewServer(privKeySigner{privKey: privKey}, testChainID, nil, maxFee, "", zap.NewNop()).Router())
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, time.Second, "", nil)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := &clienttypes.MsgUpdateClient{Signer: "neutron1relayer"}
+	tests := []struct {
+		name      string
+		fee       sdk.Coins
+		expectErr bool
+	}{
+		{name: "no fee", fee: nil},
+		{name: "fee within cap", fee: sdk.NewCoins(sdk.NewInt64Coin("untrn", 4000))},
+		{name: "fee at cap", fee: maxFee},
+		{name: "fee over cap", fee: sdk.NewCoins(sdk.NewInt64Coin("untrn", 5001)), expectErr: true},
+		{name: "fee in not allowed denom", fee: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)), expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := client.Sign(ctx, signBytesWithFee(t, msg, tt.fee))
+			if tt.expectErr && err == nil {
+				t.Fatal("expected the signer to refuse the fee")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	caCert, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}, caCert, caKey)
+	writeCert(t, dir, "client", &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	privKey := secp256k1.GenPrivKey()
+	serverTLS, err := NewServerTLSConfig(path("server.crt"), path("server.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatalf("failed to create server tls config: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(NewServer(privKeySigner{privKey: privKey}, testChainID, nil, nil, "", zap.NewNop()).Router())
+	ts.TLS = serverTLS
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientTLS, err := NewClientTLSConfig(path("ca.crt"), path("client.crt"), path("client.key"))
+	if err != nil {
+		t.Fatalf("failed to create client tls config: %v", err)
+	}
+	client, err := NewClient(ts.URL, time.Second, "", clientTLS)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	pubKey, err := client.PubKey(ctx)
+	if err != nil {
+		t.Fatalf("failed to get public key: %v", err)
+	}
+	if !pubKey.Equals(privKey.PubKey()) {
+		t.Fatalf("unexpected public key %s", pubKey)
+	}
+
+	noCertTLS, err := NewClientTLSConfig(path("ca.crt"), "", "")
+	if err != nil {
+		t.Fatalf("failed to create client tls config: %v", err)
+	}
+	noCertClient, err := NewClient(ts.URL, time.Second, "", noCertTLS)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := noCertClient.PubKey(ctx); err == nil {
+		t.Fatalf("expected the client without a certificate to be refused")
+	}
+}
+
+// writeCert writes the <name>.crt and <name>.key PEM files of a certificate signed by the parent,
+// the certificate is self-signed if the parent is nil
+func writeCert(
+	t *testing.T,
+	dir string,
+	name string,
+	template *x509.Certificate,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
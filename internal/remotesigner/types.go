@@ -0,0 +1,24 @@
+package remotesigner
+
+const (
+	PubKeyResource = "/pubkey"
+	SignResource   = "/sign"
+
+	bearerPrefix = "Bearer "
+)
+
+// SignRequest is a request to sign the SIGN_MODE_DIRECT sign bytes of a transaction
+type SignRequest struct {
+	SignBytes []byte `json:"sign_bytes"`
+}
+
+// SignResponse contains the signature and the compressed secp256k1 public key to verify it with
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+	PubKey    []byte `json:"pub_key"`
+}
+
+// PubKeyResponse contains the compressed secp256k1 public key of the signer
+type PubKeyResponse struct {
+	PubKey []byte `json:"pub_key"`
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 
 	"github.com/syndtr/goleveldb/leveldb"
 )
@@ -18,14 +19,24 @@ const (
 	SubmittedTxStatusPrefix    = "submitted_txs"
 	UnsuccessfulTxStatusPrefix = "unsuccessful_txs"
 	CachedTxs                  = "cached_txs"
+	QuorumIncidentsPrefix      = "quorum_incidents"
+	HarvestedDepositsPrefix    = "harvested_deposits"
+	UnsuccessfulKVStatusPrefix = "unsuccessful_kvs"
+	CachedKVs                  = "cached_kvs"
+	QueryTasksPrefix           = "query_tasks"
 )
 
+// maxQuorumIncidents is the number of the latest quorum incidents kept in the storage, the older ones are pruned
+const maxQuorumIncidents = 1000
+
 // LevelDBStorage Basically has a simple structure inside: we have 2 maps
 // first one : map of queryID -> last block this query has been processed
 // second one: map of queryID+txHash -> status of sent tx
 type LevelDBStorage struct {
 	mutex sync.Mutex
 	db    *leveldb.DB
+	// incidentSeq tells apart the quorum incidents recorded at the same time
+	incidentSeq uint64
 }
 
 func NewLevelDBStorage(path string) (*LevelDBStorage, error) {
@@ -170,14 +181,14 @@ func (s *LevelDBStorage) SetTxStatus(queryID uint64, hash string, neutronHash st
 		if err != nil {
 			return fmt.Errorf("failed to save txInfo into pending queue: %w", err)
 		}
-	} else if txInfo.Status == relay.Committed || txInfo.Status == relay.ErrorOnCommit {
+	} else if txInfo.Status == relay.Committed || txInfo.Status == relay.ErrorOnCommit || txInfo.Status == relay.Expired {
 		err = removeFromPendingQueue(t, neutronHash)
 		if err != nil {
 			return fmt.Errorf("failed to remove txInfo from pending queue: %w", err)
 		}
 	}
 
-	if txInfo.Status == relay.ErrorOnCommit || txInfo.Status == relay.ErrorOnSubmit {
+	if txInfo.Status == relay.ErrorOnCommit || txInfo.Status == relay.ErrorOnSubmit || txInfo.Status == relay.Expired {
 		unsuccessfulTxInfo := relay.UnsuccessfulTxInfo{
 			QueryID:         queryID,
 			SubmittedTxHash: hash,
@@ -208,6 +219,163 @@ func (s *LevelDBStorage) SetTxStatus(queryID uint64, hash string, neutronHash st
 	return err
 }
 
+func (s *LevelDBStorage) GetAllUnsuccessfulKVs() ([]*relay.UnsuccessfulKVInfo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(UnsuccessfulKVStatusPrefix)), nil)
+	defer iterator.Release()
+	// use `make` to avoid printing empty value in json as `null`
+	var kvs = make([]*relay.UnsuccessfulKVInfo, 0)
+	for iterator.Next() {
+		value := iterator.Value()
+		var kvInfo relay.UnsuccessfulKVInfo
+		err := json.Unmarshal(value, &kvInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data into UnsuccessfulKVInfo: %w", err)
+		}
+
+		kvs = append(kvs, &kvInfo)
+	}
+	return kvs, nil
+}
+
+// GetCachedKV returns the last processed KV query message
+func (s *LevelDBStorage) GetCachedKV(queryID uint64) (*relay.MessageKV, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.db.Get(constructCacheKVKey(queryID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MessageKV for query_id %d: %w", queryID, err)
+	}
+
+	var msg relay.MessageKV
+	err = json.Unmarshal(data, &msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data into MessageKV: %w", err)
+	}
+
+	return &msg, nil
+}
+
+// SetKVStatus sets status for the last KV query result submission
+// queryID can be one of 4 statuses:
+//  1. Error while submitting result - relay.ErrorOnSubmit
+//  2. result submitted successfully (temporary status, should be updated after neutron tx committed into the block) - relay.Submitted
+//     2.a) failed to commit tx into the block - relay.ErrorOnCommit
+//     2.b) tx successfully committed - relay.Committed
+//
+// KV submissions share the SubmittedTxStatusPrefix pending queue with TX submissions, so the TxSubmitChecker tracks both
+func (s *LevelDBStorage) SetKVStatus(queryID uint64, neutronHash string, kvInfo relay.SubmittedTxInfo, processedKV *relay.MessageKV) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, err := s.db.OpenTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to open leveldb transaction: %w", err)
+	}
+
+	defer t.Discard()
+
+	if processedKV != nil {
+		err = cacheProcessedKV(t, queryID, processedKV)
+		if err != nil {
+			return fmt.Errorf("failed to cache processed kv: %w", err)
+		}
+	}
+
+	if kvInfo.Status == relay.Submitted {
+		pendingTxInfo := relay.PendingSubmittedTxInfo{
+			QueryID:     queryID,
+			NeutronHash: neutronHash,
+			QueryType:   string(neutrontypes.InterchainQueryTypeKV),
+		}
+		err = saveIntoPendingQueue(t, neutronHash, pendingTxInfo)
+		if err != nil {
+			return fmt.Errorf("failed to save kvInfo into pending queue: %w", err)
+		}
+	} else if kvInfo.Status == relay.Committed || kvInfo.Status == relay.ErrorOnCommit || kvInfo.Status == relay.Expired {
+		err = removeFromPendingQueue(t, neutronHash)
+		if err != nil {
+			return fmt.Errorf("failed to remove kvInfo from pending queue: %w", err)
+		}
+	}
+
+	if kvInfo.Status == relay.ErrorOnCommit || kvInfo.Status == relay.ErrorOnSubmit || kvInfo.Status == relay.Expired {
+		unsuccessfulKVInfo := relay.UnsuccessfulKVInfo{
+			QueryID:     queryID,
+			NeutronHash: neutronHash,
+			ErrorTime:   time.Now(),
+			Status:      kvInfo.Status,
+			Message:     kvInfo.Message,
+		}
+		err = saveIntoUnsuccessfulKVQueue(t, queryID, unsuccessfulKVInfo)
+		if err != nil {
+			return fmt.Errorf("failed to save unsuccessfulKVInfo into Unsuccessful queue: %w", err)
+		}
+	}
+
+	if kvInfo.Status == relay.Committed {
+		err = removeFromUnsuccessfulKVQueue(t, queryID)
+		if err != nil {
+			return fmt.Errorf("failed to remove kvInfo from UnsuccessfulQueue: %w", err)
+		}
+	}
+
+	err = t.Commit()
+	return err
+}
+
+// RemovePendingTx removes the submitted tx from the pending queue without changing its status, e.g. if it has been
+// replaced with a rebroadcast one
+func (s *LevelDBStorage) RemovePendingTx(neutronHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, err := s.db.OpenTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to open leveldb transaction: %w", err)
+	}
+
+	defer t.Discard()
+
+	err = removeFromPendingQueue(t, neutronHash)
+	if err != nil {
+		return fmt.Errorf("failed to remove tx from pending queue: %w", err)
+	}
+
+	return t.Commit()
+}
+
+// UpdatePendingTx updates the tracking info of the submitted tx if it's still in the pending queue
+func (s *LevelDBStorage) UpdatePendingTx(txInfo relay.PendingSubmittedTxInfo) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, err := s.db.OpenTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to open leveldb transaction: %w", err)
+	}
+
+	defer t.Discard()
+
+	pending, err := t.Has(constructPendingQueueKey(txInfo.NeutronHash), nil)
+	if err != nil {
+		return fmt.Errorf("failed to check tx in pending queue: %w", err)
+	}
+	if !pending {
+		return nil
+	}
+
+	err = saveIntoPendingQueue(t, txInfo.NeutronHash, txInfo)
+	if err != nil {
+		return fmt.Errorf("failed to update tx in pending queue: %w", err)
+	}
+
+	return t.Commit()
+}
+
 // TxExists returns if tx has been processed
 func (s *LevelDBStorage) TxExists(queryID uint64, hash string) (exists bool, err error) {
 	s.mutex.Lock()
@@ -221,6 +389,163 @@ func (s *LevelDBStorage) TxExists(queryID uint64, hash string) (exists bool, err
 	return exists, nil
 }
 
+// SaveQuorumIncident saves a target chain nodes disagreement incident, only the latest maxQuorumIncidents
+// incidents are kept
+func (s *LevelDBStorage) SaveQuorumIncident(incident *relay.QuorumIncident) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return fmt.Errorf("failed to marshal QuorumIncident: %w", err)
+	}
+
+	t, err := s.db.OpenTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to open leveldb transaction: %w", err)
+	}
+
+	defer t.Discard()
+
+	s.incidentSeq++
+	err = t.Put(constructQuorumIncidentKey(incident.Time, s.incidentSeq), data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save quorum incident into the storage: %w", err)
+	}
+
+	err = pruneQuorumIncidents(t)
+	if err != nil {
+		return fmt.Errorf("failed to prune quorum incidents: %w", err)
+	}
+
+	return t.Commit()
+}
+
+// GetAllQuorumIncidents returns all recorded target chain nodes disagreement incidents ordered by time
+func (s *LevelDBStorage) GetAllQuorumIncidents() ([]*relay.QuorumIncident, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(QuorumIncidentsPrefix)), nil)
+	defer iterator.Release()
+	// use `make` to avoid printing empty value in json as `null`
+	var incidents = make([]*relay.QuorumIncident, 0)
+	for iterator.Next() {
+		var incident relay.QuorumIncident
+		err := json.Unmarshal(iterator.Value(), &incident)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data into QuorumIncident: %w", err)
+		}
+
+		incidents = append(incidents, &incident)
+	}
+	return incidents, nil
+}
+
+// SaveHarvestedDeposit saves an audit record of the deposit collected from a timed out query
+func (s *LevelDBStorage) SaveHarvestedDeposit(deposit *relay.HarvestedDeposit) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(deposit)
+	if err != nil {
+		return fmt.Errorf("failed to marshal HarvestedDeposit: %w", err)
+	}
+
+	err = s.db.Put(constructHarvestedDepositKey(deposit.Time, deposit.QueryID), data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save harvested deposit into the storage: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllHarvestedDeposits returns all recorded deposits collected from timed out queries ordered by time
+func (s *LevelDBStorage) GetAllHarvestedDeposits() ([]*relay.HarvestedDeposit, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(HarvestedDepositsPrefix)), nil)
+	defer iterator.Release()
+	// use `make` to avoid printing empty value in json as `null`
+	var deposits = make([]*relay.HarvestedDeposit, 0)
+	for iterator.Next() {
+		var deposit relay.HarvestedDeposit
+		err := json.Unmarshal(iterator.Value(), &deposit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data into HarvestedDeposit: %w", err)
+		}
+
+		deposits = append(deposits, &deposit)
+	}
+	return deposits, nil
+}
+
+// SetQueryTask saves the state of the latest task of the query
+func (s *LevelDBStorage) SetQueryTask(task *relay.QueryTask) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.putQueryTask(task)
+}
+
+// SetQueryTaskStatus updates the status of the latest task of the query, it's a no-op if the query has no task
+func (s *LevelDBStorage) SetQueryTaskStatus(queryID uint64, status relay.QueryTaskStatus) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := s.db.Get(constructQueryTaskKey(queryID), nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get query task from the storage: %w", err)
+	}
+
+	var task relay.QueryTask
+	if err := json.Unmarshal(data, &task); err != nil {
+		return fmt.Errorf("failed to unmarshal data into QueryTask: %w", err)
+	}
+	task.Status = status
+	task.UpdatedAt = time.Now()
+
+	return s.putQueryTask(&task)
+}
+
+// RemoveQueryTask removes the task state of the query, e.g. once the query is removed
+func (s *LevelDBStorage) RemoveQueryTask(queryID uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.db.Delete(constructQueryTaskKey(queryID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove query task from the storage: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllQueryTasks returns the latest task states of all the queries
+func (s *LevelDBStorage) GetAllQueryTasks() ([]*relay.QueryTask, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(QueryTasksPrefix)), nil)
+	defer iterator.Release()
+	// use `make` to avoid printing empty value in json as `null`
+	var tasks = make([]*relay.QueryTask, 0)
+	for iterator.Next() {
+		var task relay.QueryTask
+		err := json.Unmarshal(iterator.Value(), &task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data into QueryTask: %w", err)
+		}
+
+		tasks = append(tasks, &task)
+	}
+	return tasks, nil
+}
+
 // SetLastQueryHeight sets last processed block to given query
 func (s *LevelDBStorage) SetLastQueryHeight(queryID uint64, block uint64) error {
 	s.mutex.Lock()
@@ -242,6 +567,20 @@ func (s *LevelDBStorage) Close() error {
 	return nil
 }
 
+func (s *LevelDBStorage) putQueryTask(task *relay.QueryTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal QueryTask: %w", err)
+	}
+
+	err = s.db.Put(constructQueryTaskKey(task.QueryID), data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save query task into the storage: %w", err)
+	}
+
+	return nil
+}
+
 func saveIntoPendingQueue(t *leveldb.Transaction, neutronTXHash string, txInfo relay.PendingSubmittedTxInfo) error {
 	key := constructPendingQueueKey(neutronTXHash)
 	data, err := json.Marshal(txInfo)
@@ -257,6 +596,28 @@ func saveIntoPendingQueue(t *leveldb.Transaction, neutronTXHash string, txInfo r
 	return nil
 }
 
+// pruneQuorumIncidents removes the oldest quorum incidents above the maxQuorumIncidents
+func pruneQuorumIncidents(t *leveldb.Transaction) error {
+	iterator := t.NewIterator(util.BytesPrefix([]byte(QuorumIncidentsPrefix)), nil)
+	defer iterator.Release()
+
+	var keys [][]byte
+	for iterator.Next() {
+		keys = append(keys, append([]byte(nil), iterator.Key()...))
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(keys)-maxQuorumIncidents; i++ {
+		if err := t.Delete(keys[i], nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func removeFromPendingQueue(t *leveldb.Transaction, neutronTXHash string) error {
 	key := constructPendingQueueKey(neutronTXHash)
 	err := t.Delete(key, nil)
@@ -317,6 +678,58 @@ func cacheProcessedTx(t *leveldb.Transaction, queryID uint64, tXHash string, tx
 	return nil
 }
 
+func saveIntoUnsuccessfulKVQueue(t *leveldb.Transaction, queryID uint64, kvInfo relay.UnsuccessfulKVInfo) error {
+	key := constructUnsuccessfulKVQueueKey(queryID)
+	data, err := json.Marshal(kvInfo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal UnsuccessfulKVInfo: %w", err)
+	}
+
+	err = t.Put(key, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save unsuccessful kvInfo(queryID=%d) into the storage: %w", queryID, err)
+	}
+
+	return nil
+}
+
+func removeFromUnsuccessfulKVQueue(t *leveldb.Transaction, queryID uint64) error {
+	key := constructUnsuccessfulKVQueueKey(queryID)
+	err := t.Delete(key, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove UnsuccessfulKVInfo with queryID=%d: %w", queryID, err)
+	}
+
+	return nil
+}
+
+func cacheProcessedKV(t *leveldb.Transaction, queryID uint64, msg *relay.MessageKV) error {
+	key := constructCacheKVKey(queryID)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal relay.MessageKV: %w", err)
+	}
+
+	err = t.Put(key, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save cachedKV in the storage: %w", err)
+	}
+
+	return nil
+}
+
+func constructCacheKVKey(queryID uint64) []byte {
+	return append([]byte(CachedKVs), uintToBytes(queryID)...)
+}
+
+func constructQueryTaskKey(queryID uint64) []byte {
+	return append([]byte(QueryTasksPrefix), uintToBytes(queryID)...)
+}
+
+func constructUnsuccessfulKVQueueKey(queryID uint64) []byte {
+	return append([]byte(UnsuccessfulKVStatusPrefix), uintToBytes(queryID)...)
+}
+
 func constructCacheTxKey(queryID uint64, tXHash string) []byte {
 	return append([]byte(CachedTxs), constructTxStatusKey(queryID, tXHash)...)
 }
@@ -344,6 +757,17 @@ func constructPendingQueueKey(neutronTXHash string) []byte {
 	return key
 }
 
+// constructQuorumIncidentKey builds a key that keeps incidents ordered by time: zero-padded
+// nanoseconds are compared lexicographically the same way as numerically. The sequence number
+// keeps the incidents recorded at the same time apart.
+func constructQuorumIncidentKey(t time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d%020d", QuorumIncidentsPrefix, t.UnixNano(), seq))
+}
+
+func constructHarvestedDepositKey(t time.Time, queryID uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d%020d", HarvestedDepositsPrefix, t.UnixNano(), queryID))
+}
+
 func constructTxStatusKey(num uint64, str string) []byte {
 	return append(uintToBytes(num), str...)
 }
@@ -0,0 +1,62 @@
+package submit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/api/tendermint/abci"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/raw"
+)
+
+const allBalancesQueryPath = "/cosmos.bank.v1beta1.Query/AllBalances"
+
+// QueryAccount returns BaseAccount for given account address
+func QueryAccount(ctx context.Context, rpcClient rpcclient.Client, address string) (*authtypes.BaseAccount, error) {
+	request := authtypes.QueryAccountRequest{Address: address}
+	req, err := request.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling query account request for account=%s: %w", address, err)
+	}
+	simQuery := abci.RequestQuery{
+		Path: accountQueryPath,
+		Data: req,
+	}
+	res, err := rpcClient.ABCIQueryWithOptions(ctx, simQuery.Path, simQuery.Data, rpcclient.DefaultABCIQueryOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error making abci query for account=%s: %w", address, err)
+	}
+
+	if res.Response.Code != 0 {
+		return nil, fmt.Errorf("error fetching account with address=%s log=%s", address, res.Response.Log)
+	}
+
+	var response authtypes.QueryAccountResponse
+	if err := response.Unmarshal(res.Response.Value); err != nil {
+		return nil, fmt.Errorf("error unmarshalling QueryAccountResponse for account=%s: %w", address, err)
+	}
+
+	var account authtypes.BaseAccount
+	err = account.Unmarshal(response.Account.Value)
+
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling BaseAccount for account=%s: %w", address, err)
+	}
+
+	return &account, nil
+}
+
+// QueryBalances returns all the balances of given account address
+func QueryBalances(ctx context.Context, rpcClient rpcclient.Client, address string) (sdk.Coins, error) {
+	request := banktypes.QueryAllBalancesRequest{Address: address}
+	var response banktypes.QueryAllBalancesResponse
+	if err := raw.ABCIQuery(ctx, rpcClient, allBalancesQueryPath, &request, &response); err != nil {
+		return nil, fmt.Errorf("failed to query balances of account=%s: %w", address, err)
+	}
+
+	return response.Balances, nil
+}
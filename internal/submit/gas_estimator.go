@@ -0,0 +1,171 @@
+package submit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+)
+
+const (
+	// txSizeBucket is the granularity of the submitted tx size in the gas profile key, transactions
+	// of similar size are expected to consume similar gas
+	txSizeBucket = 1024
+	// maxTrackedTxs limits the number of sent transactions waiting for their execution results
+	maxTrackedTxs = 10000
+)
+
+// GasEstimator keeps a rolling profile of the gas used by the repetitive transactions (e.g. KV submissions
+// of the same query) and estimates their gas without simulation when the profile is stable enough.
+// A profile is dropped after an out of gas failure, so its transactions are simulated again.
+type GasEstimator struct {
+	window     int
+	minSamples int
+	maxSpread  float64
+
+	lock sync.Mutex
+	// profiles contains the recent gas usages of the transactions by the profile key
+	profiles map[string][]uint64
+	// sentTxs contains the profile keys of the sent transactions by their hashes
+	sentTxs map[string]string
+}
+
+// NewGasEstimator constructs a new GasEstimator keeping window recent gas usages per profile. The gas is
+// estimated if there are at least minSamples usages and the spread between the smallest and the largest
+// of them relative to the largest one is not greater than maxSpread.
+func NewGasEstimator(window uint64, minSamples uint64, maxSpread float64) (*GasEstimator, error) {
+	if minSamples == 0 || minSamples > window {
+		return nil, fmt.Errorf("gas estimation min samples must be in range [1; %d], got %d", window, minSamples)
+	}
+	if maxSpread < 0 || maxSpread > 1 {
+		return nil, fmt.Errorf("gas estimation max spread must be in range [0; 1], got %f", maxSpread)
+	}
+
+	return &GasEstimator{
+		window:     int(window),
+		minSamples: int(minSamples),
+		maxSpread:  maxSpread,
+		profiles:   make(map[string][]uint64),
+		sentTxs:    make(map[string]string),
+	}, nil
+}
+
+// Estimate returns the largest recent gas usage of the profile if the profile is confident enough
+func (e *GasEstimator) Estimate(key string) (uint64, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	samples := e.profiles[key]
+	if len(samples) < e.minSamples {
+		neutronmetrics.IncGasEstimationMiss()
+		return 0, false
+	}
+
+	minGas, maxGas := samples[0], samples[0]
+	for _, gas := range samples[1:] {
+		if gas < minGas {
+			minGas = gas
+		}
+		if gas > maxGas {
+			maxGas = gas
+		}
+	}
+	if maxGas == 0 || float64(maxGas-minGas)/float64(maxGas) > e.maxSpread {
+		neutronmetrics.IncGasEstimationMiss()
+		return 0, false
+	}
+
+	neutronmetrics.IncGasEstimationHit()
+	return maxGas, true
+}
+
+// Observe adds the gas used by a transaction of the profile, e.g. the simulated one
+func (e *GasEstimator) Observe(key string, gasUsed uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.observe(key, gasUsed)
+}
+
+// TrackTx remembers the profile of the sent transaction to learn from its execution result
+func (e *GasEstimator) TrackTx(hash string, key string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// the results of some transactions might never be reported, e.g. if the relayer is restarted
+	if len(e.sentTxs) >= maxTrackedTxs {
+		e.sentTxs = make(map[string]string)
+	}
+	e.sentTxs[strings.ToUpper(hash)] = key
+}
+
+// ObserveTxResult updates the profile of the committed transaction with its actual gas usage. The profile
+// is dropped if the transaction ran out of gas.
+func (e *GasEstimator) ObserveTxResult(neutronHash string, gasUsed int64, outOfGas bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	hash := strings.ToUpper(neutronHash)
+	key, ok := e.sentTxs[hash]
+	if !ok {
+		return
+	}
+	delete(e.sentTxs, hash)
+
+	if outOfGas {
+		delete(e.profiles, key)
+		return
+	}
+	if gasUsed > 0 {
+		e.observe(key, uint64(gasUsed))
+	}
+}
+
+func (e *GasEstimator) observe(key string, gasUsed uint64) {
+	samples := append(e.profiles[key], gasUsed)
+	if len(samples) > e.window {
+		samples = samples[len(samples)-e.window:]
+	}
+	e.profiles[key] = samples
+}
+
+// gasProfileKey returns the key of the gas profile of a transaction with the msgs. Query result submissions
+// are profiled by the query id and the number of KV results or the submitted tx size, other messages
+// by their type only.
+func gasProfileKey(msgs []sdk.Msg) string {
+	parts := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		switch m := msg.(type) {
+		case *authz.MsgExec:
+			innerMsgs, err := m.GetMessages()
+			if err != nil {
+				parts = append(parts, sdk.MsgTypeURL(msg))
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s(%s)", sdk.MsgTypeURL(msg), gasProfileKey(innerMsgs)))
+		case *neutrontypes.MsgSubmitQueryResult:
+			parts = append(parts, submitQueryResultProfileKey(m))
+		default:
+			parts = append(parts, sdk.MsgTypeURL(msg))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func submitQueryResultProfileKey(msg *neutrontypes.MsgSubmitQueryResult) string {
+	typeURL := sdk.MsgTypeURL(msg)
+	if msg.Result == nil {
+		return fmt.Sprintf("%s/%d", typeURL, msg.QueryId)
+	}
+	if msg.Result.Block != nil && msg.Result.Block.Tx != nil {
+		return fmt.Sprintf("%s/%d/tx/%d", typeURL, msg.QueryId, len(msg.Result.Block.Tx.Data)/txSizeBucket)
+	}
+
+	return fmt.Sprintf("%s/%d/kv/%d", typeURL, msg.QueryId, len(msg.Result.KvResults))
+}
@@ -0,0 +1,203 @@
+package submit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+const (
+	GasPriceSourceStatic       = "static"
+	GasPriceSourceRecentBlocks = "recent_blocks"
+	GasPriceSourceGlobalFee    = "globalfee"
+
+	globalFeeMinGasPricesQueryPath = "/gaia.globalfee.v1beta1.Query/MinimumGasPrices"
+)
+
+// GasPriceSource provides the gas prices to pay for the transactions with
+type GasPriceSource interface {
+	GasPrices(ctx context.Context) (sdk.DecCoins, error)
+}
+
+// StaticGasPrice always returns the same configured gas prices
+type StaticGasPrice struct {
+	prices sdk.DecCoins
+}
+
+func NewStaticGasPrice(prices sdk.DecCoins) *StaticGasPrice {
+	return &StaticGasPrice{prices: prices}
+}
+
+func (s *StaticGasPrice) GasPrices(_ context.Context) (sdk.DecCoins, error) {
+	return s.prices, nil
+}
+
+// RecentBlocksGasPrice estimates the gas price as a percentile of the gas prices paid by the transactions
+// in the recent blocks. The price is never lower than the minimal one.
+type RecentBlocksGasPrice struct {
+	rpcClient  rpcclient.Client
+	txDecoder  sdk.TxDecoder
+	minPrice   sdk.DecCoin
+	blocks     int64
+	percentile uint64
+
+	lock sync.Mutex
+	// prices contains the gas prices paid in the blocks of the window by height
+	prices map[int64][]sdk.Dec
+}
+
+// NewRecentBlocksGasPrice constructs a new RecentBlocksGasPrice over the last blocks, percentile is in range [0; 100]
+func NewRecentBlocksGasPrice(rpcClient rpcclient.Client, txDecoder sdk.TxDecoder, minPrice sdk.DecCoin, blocks uint64, percentile uint64) (*RecentBlocksGasPrice, error) {
+	if blocks == 0 {
+		return nil, fmt.Errorf("number of blocks to estimate gas price over must be positive")
+	}
+	if percentile > 100 {
+		return nil, fmt.Errorf("gas price percentile must be in range [0; 100], got %d", percentile)
+	}
+
+	return &RecentBlocksGasPrice{
+		rpcClient:  rpcClient,
+		txDecoder:  txDecoder,
+		minPrice:   minPrice,
+		blocks:     int64(blocks),
+		percentile: percentile,
+		prices:     make(map[int64][]sdk.Dec),
+	}, nil
+}
+
+func (r *RecentBlocksGasPrice) GasPrices(ctx context.Context) (sdk.DecCoins, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	status, err := r.rpcClient.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neutron chain status: %w", err)
+	}
+	latestHeight := status.SyncInfo.LatestBlockHeight
+
+	// blocks of the window are fetched only once
+	for height := range r.prices {
+		if height <= latestHeight-r.blocks {
+			delete(r.prices, height)
+		}
+	}
+	for height := latestHeight - r.blocks + 1; height <= latestHeight; height++ {
+		if _, ok := r.prices[height]; ok || height < 1 {
+			continue
+		}
+		prices, err := r.blockGasPrices(ctx, height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas prices of block %d: %w", height, err)
+		}
+		r.prices[height] = prices
+	}
+
+	var prices []sdk.Dec
+	for _, blockPrices := range r.prices {
+		prices = append(prices, blockPrices...)
+	}
+	if len(prices) == 0 {
+		return sdk.NewDecCoins(r.minPrice), nil
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].LT(prices[j]) })
+	price := prices[(len(prices)-1)*int(r.percentile)/100]
+	if price.LT(r.minPrice.Amount) {
+		return sdk.NewDecCoins(r.minPrice), nil
+	}
+
+	return sdk.NewDecCoins(sdk.NewDecCoinFromDec(r.minPrice.Denom, price)), nil
+}
+
+// blockGasPrices returns the gas prices in the min price denom paid by the transactions of the block
+func (r *RecentBlocksGasPrice) blockGasPrices(ctx context.Context, height int64) ([]sdk.Dec, error) {
+	block, err := r.rpcClient.Block(ctx, &height)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]sdk.Dec, 0, len(block.Block.Txs))
+	for _, txBz := range block.Block.Txs {
+		tx, err := r.txDecoder(txBz)
+		if err != nil {
+			// there might be transactions we don't know how to decode, they are not needed for the estimation
+			continue
+		}
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok || feeTx.GetGas() == 0 {
+			continue
+		}
+
+		fee := feeTx.GetFee().AmountOf(r.minPrice.Denom)
+		if fee.IsZero() {
+			continue
+		}
+		prices = append(prices, sdk.NewDecFromInt(fee).QuoInt64(int64(feeTx.GetGas())))
+	}
+
+	return prices, nil
+}
+
+// GlobalFeeGasPrice returns the minimal gas price set by the chain's globalfee module params. The price is
+// never lower than the minimal one.
+type GlobalFeeGasPrice struct {
+	rpcClient rpcclient.Client
+	minPrice  sdk.DecCoin
+}
+
+func NewGlobalFeeGasPrice(rpcClient rpcclient.Client, minPrice sdk.DecCoin) *GlobalFeeGasPrice {
+	return &GlobalFeeGasPrice{rpcClient: rpcClient, minPrice: minPrice}
+}
+
+func (g *GlobalFeeGasPrice) GasPrices(ctx context.Context) (sdk.DecCoins, error) {
+	res, err := g.rpcClient.ABCIQueryWithOptions(ctx, globalFeeMinGasPricesQueryPath, nil, rpcclient.DefaultABCIQueryOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error making abci query for globalfee minimum gas prices: %w", err)
+	}
+
+	if res.Response.Code != 0 {
+		return nil, fmt.Errorf("error fetching globalfee minimum gas prices log=%s", res.Response.Log)
+	}
+
+	var response QueryMinimumGasPricesResponse
+	if err := response.Unmarshal(res.Response.Value); err != nil {
+		return nil, fmt.Errorf("error unmarshalling globalfee minimum gas prices: %w", err)
+	}
+
+	price := response.MinimumGasPrices.AmountOf(g.minPrice.Denom)
+	if price.LT(g.minPrice.Amount) {
+		return sdk.NewDecCoins(g.minPrice), nil
+	}
+
+	return sdk.NewDecCoins(sdk.NewDecCoinFromDec(g.minPrice.Denom, price)), nil
+}
+
+// NewGasPriceSource returns the GasPriceSource of the given type. The gasPrices is the static gas price,
+// which is the minimal one for the dynamic sources.
+func NewGasPriceSource(sourceType string, gasPrices string, rpcClient rpcclient.Client, txDecoder sdk.TxDecoder,
+	recentBlocks uint64, percentile uint64) (GasPriceSource, error) {
+	prices, err := sdk.ParseDecCoins(gasPrices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gas prices %s: %w", gasPrices, err)
+	}
+	if sourceType == GasPriceSourceStatic {
+		return NewStaticGasPrice(prices), nil
+	}
+
+	// dynamic sources estimate the price in a single denom
+	if len(prices) != 1 {
+		return nil, fmt.Errorf("gas prices must contain exactly one denom for the %s gas price source, got %s", sourceType, gasPrices)
+	}
+	switch sourceType {
+	case GasPriceSourceRecentBlocks:
+		return NewRecentBlocksGasPrice(rpcClient, txDecoder, prices[0], recentBlocks, percentile)
+	case GasPriceSourceGlobalFee:
+		return NewGlobalFeeGasPrice(rpcClient, prices[0]), nil
+	default:
+		return nil, fmt.Errorf("unknown gas price source %s", sourceType)
+	}
+}
@@ -0,0 +1,55 @@
+package submit
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// QueryMinimumGasPricesResponse is the response of the gaia.globalfee.v1beta1.Query/MinimumGasPrices query:
+//
+//	message QueryMinimumGasPricesResponse {
+//	  repeated cosmos.base.v1beta1.DecCoin minimum_gas_prices = 1;
+//	}
+type QueryMinimumGasPricesResponse struct {
+	MinimumGasPrices sdk.DecCoins
+}
+
+// Unmarshal decodes the protobuf encoded response, unknown fields are skipped
+func (m *QueryMinimumGasPricesResponse) Unmarshal(bz []byte) error {
+	m.MinimumGasPrices = nil
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return fmt.Errorf("failed to decode field tag: %w", protowire.ParseError(n))
+		}
+		bz = bz[n:]
+
+		if num != 1 {
+			n = protowire.ConsumeFieldValue(num, typ, bz)
+			if n < 0 {
+				return fmt.Errorf("failed to decode field %d: %w", num, protowire.ParseError(n))
+			}
+			bz = bz[n:]
+			continue
+		}
+
+		if typ != protowire.BytesType {
+			return fmt.Errorf("unexpected wire type %d of minimum_gas_prices", typ)
+		}
+		value, n := protowire.ConsumeBytes(bz)
+		if n < 0 {
+			return fmt.Errorf("failed to decode minimum_gas_prices: %w", protowire.ParseError(n))
+		}
+		bz = bz[n:]
+
+		var price sdk.DecCoin
+		if err := price.Unmarshal(value); err != nil {
+			return fmt.Errorf("failed to unmarshal minimum gas price: %w", err)
+		}
+		m.MinimumGasPrices = append(m.MinimumGasPrices, price)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package submit
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestQueryMinimumGasPricesResponseUnmarshal(t *testing.T) {
+	prices := sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("uatom", sdk.MustNewDecFromStr("0.0025")),
+		sdk.NewDecCoinFromDec("untrn", sdk.MustNewDecFromStr("0.01")),
+	)
+	// the community pool response has the same layout: repeated DecCoin = 1
+	bz, err := (&distrtypes.QueryCommunityPoolResponse{Pool: prices}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal response: %s", err)
+	}
+	// unknown fields are skipped
+	bz = protowire.AppendTag(bz, 2, protowire.VarintType)
+	bz = protowire.AppendVarint(bz, 7)
+
+	var response QueryMinimumGasPricesResponse
+	if err := response.Unmarshal(bz); err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+	if !response.MinimumGasPrices.IsEqual(prices) {
+		t.Fatalf("expected prices %s, got %s", prices, response.MinimumGasPrices)
+	}
+
+	if err := response.Unmarshal([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Fatal("expected error for truncated response")
+	}
+}
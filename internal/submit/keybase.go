@@ -0,0 +1,75 @@
+package submit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/config"
+)
+
+// minPassphraseLength is the minimal passphrase length accepted by the keyring
+const minPassphraseLength = 8
+
+// NewKeybase opens the keyring configured by cfg: the KeyringBackend in the HomeDir. The passphrase
+// for the `file` backend is taken from the KeyringPassphraseFile or from the KeyringPassphrase.
+func NewKeybase(chainID string, cfg config.NeutronChainConfig) (keyring.Keyring, error) {
+	var userInput io.Reader
+	if cfg.KeyringBackend == keyring.BackendFile {
+		passphrase, err := keyringPassphrase(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get keyring passphrase: %w", err)
+		}
+		if passphrase == "" {
+			return nil, fmt.Errorf("keyring passphrase must be set for the %s keyring backend", keyring.BackendFile)
+		}
+		if len(passphrase) < minPassphraseLength {
+			return nil, fmt.Errorf("keyring passphrase must be at least %d characters", minPassphraseLength)
+		}
+		userInput = newPassphraseReader(passphrase)
+	}
+
+	keybase, err := keyring.New(chainID, cfg.KeyringBackend, cfg.HomeDir, userInput)
+	if err != nil {
+		return keybase, fmt.Errorf("error creating %s keybase for chainId=%s and keyringRootDir=%s: %w", cfg.KeyringBackend, chainID, cfg.HomeDir, err)
+	}
+
+	return keybase, nil
+}
+
+// keyringPassphrase returns the keyring passphrase. The passphrase file takes precedence over the
+// passphrase set directly.
+func keyringPassphrase(cfg config.NeutronChainConfig) (string, error) {
+	if cfg.KeyringPassphraseFile == "" {
+		return cfg.KeyringPassphrase, nil
+	}
+
+	data, err := os.ReadFile(cfg.KeyringPassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring passphrase file %s: %w", cfg.KeyringPassphraseFile, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// passphraseReader is an endless io.Reader repeating the passphrase line. The keyring asks for the
+// passphrase every time it's opened (twice if it's a new one), each time through a new bufio.Reader
+// on top of the same input, so a finite input could be consumed by the first read-ahead. To be safe
+// with the read-ahead, a single Read never returns more than the rest of the current line.
+type passphraseReader struct {
+	line []byte
+	pos  int
+}
+
+func newPassphraseReader(passphrase string) *passphraseReader {
+	return &passphraseReader{line: []byte(passphrase + "\n")}
+}
+
+func (r *passphraseReader) Read(p []byte) (int, error) {
+	n := copy(p, r.line[r.pos:])
+	r.pos = (r.pos + n) % len(r.line)
+	return n, nil
+}
@@ -0,0 +1,70 @@
+package submit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+const (
+	SignModeDirect    = "direct"
+	SignModeAminoJSON = "amino-json"
+)
+
+// Signer signs the transactions sent by the TxSender
+type Signer interface {
+	// PubKey returns the public key of the account the transactions are signed by
+	PubKey(ctx context.Context) (cryptotypes.PubKey, error)
+	// Sign signs the sign bytes of a transaction (encoded according to the sign mode, e.g. the SignDoc for
+	// SIGN_MODE_DIRECT) and returns the signature along with the public key to verify it with
+	Sign(ctx context.Context, signBytes []byte) ([]byte, cryptotypes.PubKey, error)
+}
+
+// KeyringSigner signs transactions locally with a key stored in the keyring
+type KeyringSigner struct {
+	keybase keyring.Keyring
+	keyName string
+}
+
+// NewKeyringSigner returns a Signer using the keyName key from the keybase
+func NewKeyringSigner(keybase keyring.Keyring, keyName string) (*KeyringSigner, error) {
+	if _, err := keybase.Key(keyName); err != nil {
+		return nil, fmt.Errorf("could not fetch key info from keychain with keyName=%s: %w", keyName, err)
+	}
+
+	return &KeyringSigner{keybase: keybase, keyName: keyName}, nil
+}
+
+func (s *KeyringSigner) PubKey(_ context.Context) (cryptotypes.PubKey, error) {
+	info, err := s.keybase.Key(s.keyName)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch key info from keychain with keyName=%s: %w", s.keyName, err)
+	}
+
+	return info.GetPubKey(), nil
+}
+
+func (s *KeyringSigner) Sign(_ context.Context, signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	signature, pubKey, err := s.keybase.Sign(s.keyName, signBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not sign with keyName=%s: %w", s.keyName, err)
+	}
+
+	return signature, pubKey, nil
+}
+
+// ParseSignMode parses the sign mode by its short name (`direct` or `amino-json`) or its full name
+// (e.g. `SIGN_MODE_DIRECT`). Only SIGN_MODE_DIRECT and SIGN_MODE_LEGACY_AMINO_JSON are supported.
+func ParseSignMode(signModeStr string) (signing.SignMode, error) {
+	switch signModeStr {
+	case SignModeDirect, signing.SignMode_SIGN_MODE_DIRECT.String():
+		return signing.SignMode_SIGN_MODE_DIRECT, nil
+	case SignModeAminoJSON, signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON.String():
+		return signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, nil
+	default:
+		return signing.SignMode_SIGN_MODE_UNSPECIFIED, fmt.Errorf("unsupported sign mode %s", signModeStr)
+	}
+}
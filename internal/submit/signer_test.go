@@ -0,0 +1,40 @@
+package submit
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+func TestParseSignMode(t *testing.T) {
+	tests := []struct {
+		signMode  string
+		expected  signing.SignMode
+		expectErr bool
+	}{
+		{signMode: SignModeDirect, expected: signing.SignMode_SIGN_MODE_DIRECT},
+		{signMode: "SIGN_MODE_DIRECT", expected: signing.SignMode_SIGN_MODE_DIRECT},
+		{signMode: SignModeAminoJSON, expected: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+		{signMode: "SIGN_MODE_LEGACY_AMINO_JSON", expected: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+		{signMode: "", expectErr: true},
+		{signMode: "textual", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signMode, func(t *testing.T) {
+			signMode, err := ParseSignMode(tt.signMode)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error for sign mode %q, got %s", tt.signMode, signMode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if signMode != tt.expected {
+				t.Fatalf("expected sign mode %s, got %s", tt.expected, signMode)
+			}
+		})
+	}
+}
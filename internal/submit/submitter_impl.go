@@ -5,8 +5,11 @@ import (
 	"fmt"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
 )
 
 // SubmitterImpl can submit proofs using `sender` as the transaction transport mechanism
@@ -14,10 +17,12 @@ type SubmitterImpl struct {
 	sender           *TxSender
 	allowKVCallbacks bool
 	clientID         string
+	// grants is nil if neither fee grants nor authz are used
+	grants relay.GrantsProvider
 }
 
-func NewSubmitterImpl(sender *TxSender, allowKVCallbacks bool, clientID string) *SubmitterImpl {
-	return &SubmitterImpl{sender: sender, allowKVCallbacks: allowKVCallbacks, clientID: clientID}
+func NewSubmitterImpl(sender *TxSender, allowKVCallbacks bool, clientID string, grants relay.GrantsProvider) *SubmitterImpl {
+	return &SubmitterImpl{sender: sender, allowKVCallbacks: allowKVCallbacks, clientID: clientID, grants: grants}
 }
 
 // SubmitKVProof submits query with proof back to Neutron chain
@@ -26,15 +31,19 @@ func (si *SubmitterImpl) SubmitKVProof(
 	height, revision, queryId uint64,
 	proof []*neutrontypes.StorageValue,
 	updateClientMsg sdk.Msg,
-) error {
+) (string, error) {
 	msgs, err := si.buildProofMsg(height, revision, queryId, si.allowKVCallbacks, proof)
 	if err != nil {
-		return fmt.Errorf("could not build proof msg: %w", err)
+		return "", fmt.Errorf("could not build proof msg: %w", err)
+	}
+
+	msgs, feeGranter, err := si.applyGrants(ctx, queryId, msgs)
+	if err != nil {
+		return "", fmt.Errorf("could not apply grants: %w", err)
 	}
 
 	msgs = append([]sdk.Msg{updateClientMsg}, msgs...)
-	_, err = si.sender.Send(ctx, msgs)
-	return err
+	return si.sender.Send(ctx, msgs, feeGranter)
 }
 
 // SubmitTxProof submits tx query with proof back to Neutron chain
@@ -44,11 +53,62 @@ func (si *SubmitterImpl) SubmitTxProof(ctx context.Context, queryId uint64, proo
 		return "", fmt.Errorf("could not build tx proof msg: %w", err)
 	}
 
-	return si.sender.Send(ctx, msgs)
+	msgs, feeGranter, err := si.applyGrants(ctx, queryId, msgs)
+	if err != nil {
+		return "", fmt.Errorf("could not apply grants: %w", err)
+	}
+
+	return si.sender.Send(ctx, msgs, feeGranter)
+}
+
+// applyGrants wraps the proof msgs into MsgExec if they are submitted on behalf of the authz granter,
+// and returns the fee granter to pay for the query result submission.
+func (si *SubmitterImpl) applyGrants(ctx context.Context, queryId uint64, msgs []sdk.Msg) ([]sdk.Msg, sdk.AccAddress, error) {
+	if si.grants == nil {
+		return msgs, nil, nil
+	}
+
+	if si.grants.AuthzGranter() != "" {
+		grantee, err := si.sender.SenderAddr()
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not fetch sender addr for building exec msg: %w", err)
+		}
+		granteeAddr, err := sdk.AccAddressFromBech32(grantee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid sender addr %s: %w", grantee, err)
+		}
+
+		execMsg := authz.NewMsgExec(granteeAddr, msgs)
+		msgs = []sdk.Msg{&execMsg}
+	}
+
+	feeGranter, err := si.grants.FeeGranter(ctx, queryId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get fee granter for query=%d: %w", queryId, err)
+	}
+	if feeGranter == "" {
+		return msgs, nil, nil
+	}
+
+	feeGranterAddr, err := sdk.AccAddressFromBech32(feeGranter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid fee granter addr %s: %w", feeGranter, err)
+	}
+
+	return msgs, feeGranterAddr, nil
+}
+
+// submitterAddr returns the address MsgSubmitQueryResult is sent from
+func (si *SubmitterImpl) submitterAddr() (string, error) {
+	if si.grants != nil && si.grants.AuthzGranter() != "" {
+		return si.grants.AuthzGranter(), nil
+	}
+
+	return si.sender.SenderAddr()
 }
 
 func (si *SubmitterImpl) buildProofMsg(height, revision, queryId uint64, allowKVCallbacks bool, proof []*neutrontypes.StorageValue) ([]sdk.Msg, error) {
-	senderAddr, err := si.sender.SenderAddr()
+	senderAddr, err := si.submitterAddr()
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch sender addr for building proof msg: %w", err)
 	}
@@ -71,7 +131,7 @@ func (si *SubmitterImpl) buildProofMsg(height, revision, queryId uint64, allowKV
 }
 
 func (si *SubmitterImpl) buildTxProofMsg(queryId uint64, proof *neutrontypes.Block) ([]sdk.Msg, error) {
-	senderAddr, err := si.sender.SenderAddr()
+	senderAddr, err := si.submitterAddr()
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch sender addr for building tx proof msg: %w", err)
 	}
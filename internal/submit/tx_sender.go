@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
-	"strings"
+	"math/big"
+	"regexp"
+	"strconv"
 	"sync"
 
 	tmtypes "github.com/tendermint/tendermint/types"
@@ -14,79 +16,165 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/codec"
-	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 	authtxtypes "github.com/cosmos/cosmos-sdk/x/auth/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/config"
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
 )
 
 const (
 	accountQueryPath             = "/cosmos.auth.v1beta1.Query/Account"
 	simulateQueryPath            = "/cosmos.tx.v1beta1.Service/Simulate"
 	IncorrectAccountSequenceCode = 32
+
+	BroadcastModeSync   = "sync"
+	BroadcastModeAsync  = "async"
+	BroadcastModeCommit = "commit"
 )
 
+// sequenceMismatchRegexp matches the error of a transaction with a sequence the chain doesn't expect
+var sequenceMismatchRegexp = regexp.MustCompile(`account sequence mismatch, expected (\d+), got \d+`)
+
+// inFlightTx is a broadcast transaction which is not known to be committed yet
+type inFlightTx struct {
+	sequence      uint64
+	timeoutHeight uint64
+}
+
+// TxSender signs and broadcasts transactions. The transactions are pipelined: gas is calculated concurrently
+// and only signing and broadcasting are serialized, so several transactions with consecutive sequences
+// can be in the mempool at the same time.
 type TxSender struct {
 	lock          sync.Mutex
 	sequence      uint64
 	accountNumber uint64
-	keybase       keyring.Keyring
+	signer        Signer
+	pubKey        cryptotypes.PubKey
+	senderAddr    string
 	baseTxf       tx.Factory
 	txConfig      client.TxConfig
 	rpcClient     rpcclient.Client
 	chainID       string
-	signKeyName   string
-	gasPrices     string
 	gasLimit      uint64
 	logger        *zap.Logger
-}
-
-func TestKeybase(chainID string, keyringRootDir string) (keyring.Keyring, error) {
-	keybase, err := keyring.New(chainID, "test", keyringRootDir, nil)
-	if err != nil {
-		return keybase, fmt.Errorf("error creating keybase for chainId=%s and keyringRootDir=%s: %w", chainID, keyringRootDir, err)
-	}
-
-	return keybase, nil
+	// inFlight contains the transactions broadcast with a timeout height ordered by sequence, they are used to
+	// detect expired transactions blocking the later sequences
+	inFlight []inFlightTx
+	// gasPriceSource provides the gas prices, the minGasPrices are used if it fails
+	gasPriceSource GasPriceSource
+	minGasPrices   sdk.DecCoins
+	// the gas prices are raised by gasPriceEscalationFactor up to the gasPriceCap if a transaction
+	// is rejected for insufficient fees, an empty cap disables the escalation
+	gasPriceCap              sdk.DecCoins
+	gasPriceEscalationFactor sdk.Dec
+	// gasEstimator allows to skip the simulation of the repetitive transactions, nil disables it
+	gasEstimator *GasEstimator
+	// broadcastMode is one of sync, async or commit
+	broadcastMode string
+	// txTimeoutBlocks is the number of blocks the transaction can be included within, 0 disables the timeout
+	txTimeoutBlocks uint64
 }
 
 func NewTxSender(
 	ctx context.Context,
 	rpcClient rpcclient.Client,
 	marshaller codec.ProtoCodecMarshaler,
-	keybase keyring.Keyring,
+	signer Signer,
 	cfg config.NeutronChainConfig,
 	logger *zap.Logger,
 	neutronChainID string,
 ) (*TxSender, error) {
+	pubKey, err := signer.PubKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer public key: %w", err)
+	}
+
 	txConfig := authtxtypes.NewTxConfig(marshaller, authtxtypes.DefaultSignModes)
+	gasPriceSource, err := NewGasPriceSource(cfg.GasPriceSource, cfg.GasPrices, rpcClient, txConfig.TxDecoder(),
+		cfg.GasPriceRecentBlocks, cfg.GasPricePercentile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gas price source: %w", err)
+	}
+	minGasPrices, err := sdk.ParseDecCoins(cfg.GasPrices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gas prices %s: %w", cfg.GasPrices, err)
+	}
+	gasPriceCap, err := sdk.ParseDecCoins(cfg.GasPriceCap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gas price cap %s: %w", cfg.GasPriceCap, err)
+	}
+	gasPriceEscalationFactor, err := sdk.NewDecFromStr(strconv.FormatFloat(cfg.GasPriceEscalationFactor, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas price escalation factor %f: %w", cfg.GasPriceEscalationFactor, err)
+	}
+	if !gasPriceCap.Empty() && gasPriceEscalationFactor.LTE(sdk.OneDec()) {
+		return nil, fmt.Errorf("gas price escalation factor must be greater than 1, got %s", gasPriceEscalationFactor)
+	}
+
+	signMode, err := ParseSignMode(cfg.SignModeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign mode: %w", err)
+	}
+
+	switch cfg.BroadcastMode {
+	case BroadcastModeSync, BroadcastModeCommit:
+	case BroadcastModeAsync:
+		// rejected async transactions are not noticed, so only the timeout can unblock the later sequences
+		if cfg.TxTimeoutBlocks == 0 {
+			return nil, fmt.Errorf("tx timeout blocks must be set for the %s broadcast mode", BroadcastModeAsync)
+		}
+	default:
+		return nil, fmt.Errorf("unknown broadcast mode %s", cfg.BroadcastMode)
+	}
+
+	var gasEstimator *GasEstimator
+	if cfg.GasEstimationWindow > 0 {
+		gasEstimator, err = NewGasEstimator(cfg.GasEstimationWindow, cfg.GasEstimationMinSamples, cfg.GasEstimationMaxSpread)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gas estimator: %w", err)
+		}
+	}
+
 	baseTxf := tx.Factory{}.
-		WithKeybase(keybase).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithSignMode(signMode).
 		WithTxConfig(txConfig).
 		WithChainID(neutronChainID).
 		WithGasAdjustment(cfg.GasAdjustment).
 		WithGasPrices(cfg.GasPrices)
 
 	txs := &TxSender{
-		lock:        sync.Mutex{},
-		keybase:     keybase,
-		txConfig:    txConfig,
-		baseTxf:     baseTxf,
-		rpcClient:   rpcClient,
-		chainID:     neutronChainID,
-		signKeyName: cfg.SignKeyName,
-		gasPrices:   cfg.GasPrices,
-		gasLimit:    cfg.GasLimit,
-		logger:      logger,
-	}
-	err := txs.refreshAccountInfo(ctx)
+		lock:       sync.Mutex{},
+		signer:     signer,
+		pubKey:     pubKey,
+		senderAddr: sdk.AccAddress(pubKey.Address()).String(),
+		txConfig:   txConfig,
+		baseTxf:    baseTxf,
+		rpcClient:  rpcClient,
+		chainID:    neutronChainID,
+		gasLimit:   cfg.GasLimit,
+		logger:     logger,
+
+		gasPriceSource:           gasPriceSource,
+		minGasPrices:             minGasPrices,
+		gasPriceCap:              gasPriceCap,
+		gasPriceEscalationFactor: gasPriceEscalationFactor,
+		gasEstimator:             gasEstimator,
+		broadcastMode:            cfg.BroadcastMode,
+		txTimeoutBlocks:          cfg.TxTimeoutBlocks,
+	}
+	err = txs.refreshAccountInfo(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init tx sender: %w", err)
 	}
@@ -106,127 +194,348 @@ func (txs *TxSender) refreshAccountInfo(ctx context.Context) error {
 	}
 	txs.accountNumber = account.AccountNumber
 	txs.sequence = account.Sequence
+	txs.inFlight = nil
 	return nil
 }
 
-// Send builds transaction with calculated input msgs, calculated gas and fees, signs it and submits to chain
-func (txs *TxSender) Send(ctx context.Context, msgs []sdk.Msg) (string, error) {
+// Send builds transaction with calculated input msgs, calculated gas and fees, signs it and submits to chain.
+// The fees are paid by the feeGranter if it's not empty.
+func (txs *TxSender) Send(ctx context.Context, msgs []sdk.Msg, feeGranter sdk.AccAddress) (string, error) {
+	if err := checkSignMode(txs.baseTxf.SignMode(), msgs); err != nil {
+		return "", err
+	}
+
+	profileKey := gasProfileKey(msgs)
+	gasNeeded, err := txs.estimateGas(ctx, feeGranter, profileKey, msgs)
+	if err != nil {
+		return "", fmt.Errorf("error calculating gas: %w", err)
+	}
+
+	if txs.gasLimit > 0 && gasNeeded > txs.gasLimit {
+		return "", fmt.Errorf("exceeds gas limit: gas needed %d, gas limit %d", gasNeeded, txs.gasLimit)
+	}
+
+	var latestHeight, timeoutHeight uint64
+	if txs.txTimeoutBlocks > 0 {
+		status, err := txs.rpcClient.Status(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get neutron chain status: %w", err)
+		}
+		latestHeight = uint64(status.SyncInfo.LatestBlockHeight)
+		timeoutHeight = latestHeight + txs.txTimeoutBlocks
+	}
+
+	gasPrices := txs.gasPrices(ctx)
+
 	txs.lock.Lock()
 	defer txs.lock.Unlock()
 
+	if err := txs.recoverExpiredTxs(ctx, latestHeight); err != nil {
+		return "", fmt.Errorf("failed to recover expired transactions: %w", err)
+	}
+
 	txf := txs.baseTxf.
 		WithAccountNumber(txs.accountNumber).
-		WithSequence(txs.sequence)
+		WithSequence(txs.sequence).
+		WithTimeoutHeight(timeoutHeight)
 
-	gasNeeded, err := txs.calculateGas(ctx, txf, msgs...)
-	if err != nil {
-		// at this point error code for "incorrect account sequence" is 18 = "invalid request"
-		// it's a very common error code to rely on, hence we have to rely on error message
-		if strings.Contains(err.Error(), "incorrect account sequence") {
+	for {
+		txf = txf.
+			WithGas(gasNeeded).
+			WithGasPrices(gasPrices.String())
+
+		bz, err := txs.signAndBuildTxBz(ctx, txf, feeGranter, msgs)
+		if err != nil {
+			return "", fmt.Errorf("could not sign and build tx bz: %w", err)
+		}
+
+		res, err := txs.broadcast(ctx, bz)
+		if err != nil {
+			return "", fmt.Errorf("error broadcasting %s transaction: %w", txs.broadcastMode, err)
+		}
+
+		if res.Code == 0 {
+			if timeoutHeight > 0 {
+				txs.inFlight = append(txs.inFlight, inFlightTx{sequence: txs.sequence, timeoutHeight: timeoutHeight})
+			}
+			txs.sequence += 1
+			for _, fee := range calculateFees(gasPrices, gasNeeded) {
+				amount, _ := new(big.Float).SetInt(fee.Amount.BigInt()).Float64()
+				neutronmetrics.AddFeesSpent(fee.Denom, amount)
+			}
+			hash := hex.EncodeToString(tmtypes.Tx(bz).Hash())
+			if txs.gasEstimator != nil {
+				txs.gasEstimator.TrackTx(hash, profileKey)
+			}
+			return hash, nil
+		}
+
+		// the transaction is rejected by CheckTx, so the same sequence can be used with the raised gas prices
+		if res.Codespace == sdkerrors.RootCodespace && res.Code == sdkerrors.ErrInsufficientFee.ABCICode() {
+			if escalated, ok := txs.escalateGasPrices(gasPrices); ok {
+				txs.logger.Info("transaction rejected for insufficient fees, raising gas prices",
+					zap.String("gas_prices", gasPrices.String()), zap.String("escalated_gas_prices", escalated.String()))
+				gasPrices = escalated
+				setGasPricesMetrics(gasPrices)
+				continue
+			}
+		}
+
+		if res.Code == IncorrectAccountSequenceCode {
 			errInit := txs.refreshAccountInfo(ctx)
 			if errInit != nil {
-				return "", fmt.Errorf("error calculating gas: failed to reinit sender: %w", errInit)
+				return "", fmt.Errorf("error broadcasting %s transaction: failed to reinit sender: %w", txs.broadcastMode, errInit)
 			}
 			txs.logger.Info("sender reinitialized successfully (account sequence reset)")
 		}
-		return "", fmt.Errorf("error calculating gas: %w", err)
+		return "", fmt.Errorf("error broadcasting %s transaction: %w", txs.broadcastMode, &relay.TxError{
+			Codespace: res.Codespace,
+			Code:      res.Code,
+			Log:       res.Log,
+		})
 	}
+}
 
-	if txs.gasLimit > 0 && gasNeeded > txs.gasLimit {
-		return "", fmt.Errorf("exceeds gas limit: gas needed %d, gas limit %d", gasNeeded, txs.gasLimit)
+// checkSignMode makes sure the msgs can be signed in the sign mode. Messages without the amino JSON
+// representation (e.g. MsgUpdateClient) can be signed in SIGN_MODE_DIRECT only.
+func checkSignMode(signMode signing.SignMode, msgs []sdk.Msg) error {
+	if signMode != signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON {
+		return nil
 	}
 
-	txf = txf.
-		WithGas(gasNeeded).
-		WithGasPrices(txs.gasPrices)
+	for _, msg := range msgs {
+		if _, ok := msg.(legacytx.LegacyMsg); !ok {
+			return fmt.Errorf("message %s can't be signed in %s sign mode", sdk.MsgTypeURL(msg), SignModeAminoJSON)
+		}
+	}
 
-	bz, err := txs.signAndBuildTxBz(txf, msgs)
-	if err != nil {
-		return "", fmt.Errorf("could not sign and build tx bz: %w", err)
+	return nil
+}
+
+// broadcast broadcasts the transaction in the configured mode. The result of the async broadcast is always
+// successful, the commit broadcast result is the CheckTx one (the DeliverTx result is checked later as for
+// the other modes).
+func (txs *TxSender) broadcast(ctx context.Context, bz []byte) (*coretypes.ResultBroadcastTx, error) {
+	switch txs.broadcastMode {
+	case BroadcastModeAsync:
+		return txs.rpcClient.BroadcastTxAsync(ctx, bz)
+	case BroadcastModeCommit:
+		res, err := txs.rpcClient.BroadcastTxCommit(ctx, bz)
+		if err != nil {
+			return nil, err
+		}
+		return &coretypes.ResultBroadcastTx{
+			Code:      res.CheckTx.Code,
+			Data:      res.CheckTx.Data,
+			Log:       res.CheckTx.Log,
+			Codespace: res.CheckTx.Codespace,
+			Hash:      res.Hash,
+		}, nil
+	default:
+		return txs.rpcClient.BroadcastTxSync(ctx, bz)
 	}
+}
 
-	res, err := txs.rpcClient.BroadcastTxSync(ctx, bz)
+// recoverExpiredTxs resets the sequence to the committed one if the oldest in-flight transaction has expired
+// without being committed, since none of the later ones can be committed either. It must be called under the lock.
+func (txs *TxSender) recoverExpiredTxs(ctx context.Context, latestHeight uint64) error {
+	// the transaction can still be included into the next block
+	if len(txs.inFlight) == 0 || latestHeight < txs.inFlight[0].timeoutHeight {
+		return nil
+	}
+
+	account, err := txs.queryAccount(ctx, txs.senderAddr)
 	if err != nil {
-		return "", fmt.Errorf("error broadcasting sync transaction: %w", err)
+		return fmt.Errorf("error fetching account: %w", err)
 	}
 
-	if res.Code == 0 {
-		txs.sequence += 1
-		return hex.EncodeToString(tmtypes.Tx(bz).Hash()), nil
+	for len(txs.inFlight) > 0 && txs.inFlight[0].sequence < account.Sequence {
+		txs.inFlight = txs.inFlight[1:]
+	}
+	if len(txs.inFlight) > 0 && latestHeight >= txs.inFlight[0].timeoutHeight {
+		txs.logger.Warn("in-flight transactions expired, resetting account sequence",
+			zap.Uint64("sequence", txs.sequence), zap.Uint64("committed_sequence", account.Sequence))
+		txs.sequence = account.Sequence
+		txs.inFlight = nil
 	}
 
-	if res.Code == IncorrectAccountSequenceCode {
-		errInit := txs.refreshAccountInfo(ctx)
-		if errInit != nil {
-			return "", fmt.Errorf("error broadcasting sync transaction: failed to reinit sender: %w", errInit)
-		}
-		txs.logger.Info("sender reinitialized successfully (account sequence reset)")
+	return nil
+}
+
+// GasObserver returns the observer of the sent transactions gas usage, or nil if the gas estimation is disabled
+func (txs *TxSender) GasObserver() relay.GasObserver {
+	if txs.gasEstimator == nil {
+		return nil
 	}
-	return "", fmt.Errorf("error broadcasting sync transaction: log=%s", res.Log)
+
+	return txs.gasEstimator
 }
 
-func (txs *TxSender) SenderAddr() (string, error) {
-	info, err := txs.keybase.Key(txs.signKeyName)
+// estimateGas returns the gas for the transaction with the msgs from its gas profile if it's confident enough,
+// otherwise the transaction is simulated
+func (txs *TxSender) estimateGas(ctx context.Context, feeGranter sdk.AccAddress, profileKey string, msgs []sdk.Msg) (uint64, error) {
+	if txs.gasEstimator != nil {
+		if gasUsed, ok := txs.gasEstimator.Estimate(profileKey); ok {
+			return uint64(txs.baseTxf.GasAdjustment() * float64(gasUsed)), nil
+		}
+	}
+
+	gasUsed, err := txs.simulate(ctx, feeGranter, msgs)
 	if err != nil {
-		return "", fmt.Errorf("could not fetch sender info from keychain with signKeyName=%s: %w", txs.signKeyName, err)
+		return 0, err
+	}
+	if txs.gasEstimator != nil {
+		txs.gasEstimator.Observe(profileKey, gasUsed)
 	}
 
-	return info.GetAddress().String(), nil
+	return uint64(txs.baseTxf.GasAdjustment() * float64(gasUsed)), nil
 }
 
-// queryAccount returns BaseAccount for given account address
-func (txs *TxSender) queryAccount(ctx context.Context, address string) (*authtypes.BaseAccount, error) {
-	request := authtypes.QueryAccountRequest{Address: address}
-	req, err := request.Marshal()
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling query account request for account=%s: %w", address, err)
+// simulate simulates the transaction with the next sequence to broadcast. The simulation runs against the check
+// state, which already includes the transactions accepted to the mempool, so it expects the same sequence as
+// the broadcast. The simulation is retried once with the expected sequence if the sequence is out of sync,
+// the sequence itself is reset on the broadcast.
+func (txs *TxSender) simulate(ctx context.Context, feeGranter sdk.AccAddress, msgs []sdk.Msg) (uint64, error) {
+	txs.lock.Lock()
+	sequence := txs.sequence
+	txs.lock.Unlock()
+
+	txf := txs.baseTxf.WithSequence(sequence)
+	gasUsed, err := txs.calculateGas(ctx, txf, feeGranter, msgs...)
+	if err == nil {
+		return gasUsed, nil
 	}
-	simQuery := abci.RequestQuery{
-		Path: accountQueryPath,
-		Data: req,
+
+	// at this point error code for "incorrect account sequence" is 18 = "invalid request"
+	// it's a very common error code to rely on, hence we have to rely on error message
+	matches := sequenceMismatchRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return 0, err
 	}
-	res, err := txs.rpcClient.ABCIQueryWithOptions(ctx, simQuery.Path, simQuery.Data, rpcclient.DefaultABCIQueryOptions)
+	expectedSequence, errParse := strconv.ParseUint(matches[1], 10, 64)
+	if errParse != nil {
+		return 0, err
+	}
+
+	return txs.calculateGas(ctx, txf.WithSequence(expectedSequence), feeGranter, msgs...)
+}
+
+// gasPrices returns the gas prices from the gas price source, or the minimal gas prices if it fails
+func (txs *TxSender) gasPrices(ctx context.Context) sdk.DecCoins {
+	gasPrices, err := txs.gasPriceSource.GasPrices(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error making abci query for account=%s: %w", address, err)
+		txs.logger.Warn("failed to get gas prices, using the minimal gas prices", zap.Error(err))
+		gasPrices = txs.minGasPrices
 	}
+	setGasPricesMetrics(gasPrices)
 
-	if res.Response.Code != 0 {
-		return nil, fmt.Errorf("error fetching account with address=%s log=%s", address, res.Response.Log)
+	return gasPrices
+}
+
+// escalateGasPrices raises the gas prices by the escalation factor up to the cap. It returns false if
+// none of the prices can be raised.
+func (txs *TxSender) escalateGasPrices(gasPrices sdk.DecCoins) (sdk.DecCoins, bool) {
+	escalated := sdk.NewDecCoins()
+	raised := false
+	for _, price := range gasPrices {
+		next := price.Amount.Mul(txs.gasPriceEscalationFactor)
+		if limit := txs.gasPriceCap.AmountOf(price.Denom); next.GT(limit) {
+			next = limit
+		}
+		if next.GT(price.Amount) {
+			raised = true
+		} else {
+			next = price.Amount
+		}
+		escalated = escalated.Add(sdk.NewDecCoinFromDec(price.Denom, next))
 	}
 
-	var response authtypes.QueryAccountResponse
-	if err := response.Unmarshal(res.Response.Value); err != nil {
-		return nil, fmt.Errorf("error unmarshalling QueryAccountResponse for account=%s: %w", address, err)
+	return escalated, raised
+}
+
+// calculateFees returns the fees for the gas with the gas prices the same way tx.Factory does
+func calculateFees(gasPrices sdk.DecCoins, gas uint64) sdk.Coins {
+	glDec := sdk.NewDec(int64(gas))
+	fees := make(sdk.Coins, len(gasPrices))
+	for i, gp := range gasPrices {
+		fee := gp.Amount.Mul(glDec)
+		fees[i] = sdk.NewCoin(gp.Denom, fee.Ceil().RoundInt())
 	}
 
-	var account authtypes.BaseAccount
-	err = account.Unmarshal(response.Account.Value)
+	return fees
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling BaseAccount for account=%s: %w", address, err)
+func setGasPricesMetrics(gasPrices sdk.DecCoins) {
+	for _, price := range gasPrices {
+		amount, err := price.Amount.Float64()
+		if err == nil {
+			neutronmetrics.SetGasPrice(price.Denom, amount)
+		}
 	}
+}
+
+func (txs *TxSender) SenderAddr() (string, error) {
+	return txs.senderAddr, nil
+}
 
-	return &account, nil
+// queryAccount returns BaseAccount for given account address
+func (txs *TxSender) queryAccount(ctx context.Context, address string) (*authtypes.BaseAccount, error) {
+	return QueryAccount(ctx, txs.rpcClient, address)
 }
 
-func (txs *TxSender) signAndBuildTxBz(txf tx.Factory, msgs []sdk.Msg) ([]byte, error) {
+func (txs *TxSender) signAndBuildTxBz(ctx context.Context, txf tx.Factory, feeGranter sdk.AccAddress, msgs []sdk.Msg) ([]byte, error) {
+	// the amino JSON sign bytes of a message without the amino JSON representation can't be built
+	if err := checkSignMode(txf.SignMode(), msgs); err != nil {
+		return nil, err
+	}
+
 	txBuilder, err := txf.BuildUnsignedTx(msgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build transaction builder: %w", err)
 	}
+	txBuilder.SetFeeGranter(feeGranter)
+
+	// For SIGN_MODE_DIRECT the signer infos are a part of the sign bytes, so we have to set the
+	// signature with an empty signature data first (the same way tx.Sign does).
+	sig := signing.SignatureV2{
+		PubKey:   txs.pubKey,
+		Data:     &signing.SingleSignatureData{SignMode: txf.SignMode()},
+		Sequence: txf.Sequence(),
+	}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return nil, fmt.Errorf("error setting empty signature: %w", err)
+	}
 
-	err = tx.Sign(txf, txs.signKeyName, txBuilder, false)
+	signerData := authsigning.SignerData{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}
+	signBytes, err := txs.txConfig.SignModeHandler().GetSignBytes(txf.SignMode(), signerData, txBuilder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("error getting sign bytes: %w", err)
+	}
 
+	signature, pubKey, err := txs.signer.Sign(ctx, signBytes)
 	if err != nil {
 		return nil, fmt.Errorf("error signing transaction: %w", err)
 	}
+	if !pubKey.Equals(txs.pubKey) {
+		return nil, fmt.Errorf("transaction is signed with unexpected public key %s", pubKey)
+	}
+
+	sig.Data = &signing.SingleSignatureData{SignMode: txf.SignMode(), Signature: signature}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return nil, fmt.Errorf("error setting signature: %w", err)
+	}
 
 	bz, err := txs.txConfig.TxEncoder()(txBuilder.GetTx())
 	return bz, err
 }
 
-func (txs *TxSender) calculateGas(ctx context.Context, txf tx.Factory, msgs ...sdk.Msg) (uint64, error) {
-	simulation, err := txs.buildSimulationTx(txf, msgs...)
+// calculateGas simulates the transaction and returns the gas used by it
+func (txs *TxSender) calculateGas(ctx context.Context, txf tx.Factory, feeGranter sdk.AccAddress, msgs ...sdk.Msg) (uint64, error) {
+	simulation, err := txs.buildSimulationTx(txf, feeGranter, msgs...)
 	if err != nil {
 		return 0, fmt.Errorf("error building simulation tx: %w", err)
 	}
@@ -240,6 +549,14 @@ func (txs *TxSender) calculateGas(ctx context.Context, txf tx.Factory, msgs ...s
 		return 0, fmt.Errorf("error making abci query for gas calculation: %w", err)
 	}
 
+	if res.Response.Code != 0 {
+		return 0, fmt.Errorf("error simulating transaction: %w", &relay.TxError{
+			Codespace: res.Response.Codespace,
+			Code:      res.Response.Code,
+			Log:       res.Response.Log,
+		})
+	}
+
 	var simRes txtypes.SimulateResponse
 
 	if err := simRes.Unmarshal(res.Response.Value); err != nil {
@@ -249,16 +566,18 @@ func (txs *TxSender) calculateGas(ctx context.Context, txf tx.Factory, msgs ...s
 		return 0, fmt.Errorf("no result in simulation response with log=%s code=%d", res.Response.Log, res.Response.Code)
 	}
 
-	return uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
+	return simRes.GasInfo.GasUsed, nil
 }
 
 // buildSimulationTx creates an unsigned tx with an empty single signature and returns
 // the encoded transaction or an error if the unsigned transaction cannot be built.
-func (txs *TxSender) buildSimulationTx(txf tx.Factory, msgs ...sdk.Msg) ([]byte, error) {
+func (txs *TxSender) buildSimulationTx(txf tx.Factory, feeGranter sdk.AccAddress, msgs ...sdk.Msg) ([]byte, error) {
 	txb, err := txs.baseTxf.BuildUnsignedTx(msgs...)
 	if err != nil {
 		return nil, fmt.Errorf("error building unsigned tx for simulation: %w", err)
 	}
+	// the fee grant is used in the ante handler, so it affects the gas consumption
+	txb.SetFeeGranter(feeGranter)
 
 	// Create an empty signature literal as the ante handler will populate with a
 	// sentinel pubkey.
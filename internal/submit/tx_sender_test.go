@@ -0,0 +1,222 @@
+package submit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtxtypes "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/raw"
+)
+
+// privKeySigner signs the transactions with a private key in memory
+type privKeySigner struct {
+	privKey cryptotypes.PrivKey
+}
+
+func (s privKeySigner) PubKey(_ context.Context) (cryptotypes.PubKey, error) {
+	return s.privKey.PubKey(), nil
+}
+
+func (s privKeySigner) Sign(_ context.Context, signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	signature, err := s.privKey.Sign(signBytes)
+	return signature, s.privKey.PubKey(), err
+}
+
+func newTestTxSender(signMode signing.SignMode) *TxSender {
+	privKey := secp256k1.GenPrivKey()
+	txConfig := authtxtypes.NewTxConfig(raw.MakeCodecDefault().Marshaller, authtxtypes.DefaultSignModes)
+
+	return &TxSender{
+		signer:     privKeySigner{privKey: privKey},
+		pubKey:     privKey.PubKey(),
+		senderAddr: sdk.AccAddress(privKey.PubKey().Address()).String(),
+		txConfig:   txConfig,
+		baseTxf: tx.Factory{}.
+			WithSignMode(signMode).
+			WithTxConfig(txConfig).
+			WithChainID("neutron-test-1"),
+	}
+}
+
+func newTestMsgSend(from string) *banktypes.MsgSend {
+	to := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()).String()
+	return banktypes.NewMsgSend(sdk.MustAccAddressFromBech32(from), sdk.MustAccAddressFromBech32(to),
+		sdk.NewCoins(sdk.NewInt64Coin("untrn", 1)))
+}
+
+func TestCheckSignMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		signMode  signing.SignMode
+		msg       func(txs *TxSender) sdk.Msg
+		expectErr bool
+	}{
+		{
+			name:     "direct legacy msg",
+			signMode: signing.SignMode_SIGN_MODE_DIRECT,
+			msg:      func(txs *TxSender) sdk.Msg { return newTestMsgSend(txs.senderAddr) },
+		},
+		{
+			name:     "direct msg without amino json",
+			signMode: signing.SignMode_SIGN_MODE_DIRECT,
+			msg:      func(txs *TxSender) sdk.Msg { return &clienttypes.MsgUpdateClient{Signer: txs.senderAddr} },
+		},
+		{
+			name:     "amino json legacy msg",
+			signMode: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+			msg:      func(txs *TxSender) sdk.Msg { return newTestMsgSend(txs.senderAddr) },
+		},
+		{
+			name:      "amino json msg without amino json",
+			signMode:  signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+			msg:       func(txs *TxSender) sdk.Msg { return &clienttypes.MsgUpdateClient{Signer: txs.senderAddr} },
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := newTestTxSender(tt.signMode)
+			err := checkSignMode(tt.signMode, []sdk.Msg{tt.msg(txs)})
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+// newTestSubmitQueryResult returns the TX query result submission, the KV one is the same message with
+// the KV results instead of the block
+func newTestSubmitQueryResult(sender string) *neutrontypes.MsgSubmitQueryResult {
+	return &neutrontypes.MsgSubmitQueryResult{
+		QueryId:  1,
+		Sender:   sender,
+		ClientId: "07-tendermint-0",
+		Result: &neutrontypes.QueryResult{
+			Block: &neutrontypes.Block{Tx: &neutrontypes.TxValue{Data: []byte("tx")}},
+		},
+	}
+}
+
+func TestSignAndBuildTxBz(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs func(txs *TxSender) []sdk.Msg
+		// aminoJSONErr is true if the msgs can't be signed in the amino JSON sign mode
+		aminoJSONErr bool
+	}{
+		{
+			name: "send",
+			msgs: func(txs *TxSender) []sdk.Msg { return []sdk.Msg{newTestMsgSend(txs.senderAddr)} },
+		},
+		{
+			name: "tx query result",
+			msgs: func(txs *TxSender) []sdk.Msg { return []sdk.Msg{newTestSubmitQueryResult(txs.senderAddr)} },
+		},
+		{
+			name: "kv query result with client update",
+			msgs: func(txs *TxSender) []sdk.Msg {
+				return []sdk.Msg{
+					&clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0", Signer: txs.senderAddr},
+					newTestSubmitQueryResult(txs.senderAddr),
+				}
+			},
+			aminoJSONErr: true,
+		},
+		{
+			name: "query removal",
+			msgs: func(txs *TxSender) []sdk.Msg {
+				return []sdk.Msg{&neutrontypes.MsgRemoveInterchainQueryRequest{QueryId: 1, Sender: txs.senderAddr}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, signMode := range []signing.SignMode{signing.SignMode_SIGN_MODE_DIRECT, signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON} {
+			t.Run(tt.name+" "+signMode.String(), func(t *testing.T) {
+				txs := newTestTxSender(signMode)
+				txf := txs.baseTxf.
+					WithAccountNumber(7).
+					WithSequence(42).
+					WithGas(200000).
+					WithGasPrices("0.025untrn")
+				msgs := tt.msgs(txs)
+
+				bz, err := txs.signAndBuildTxBz(context.Background(), txf, nil, msgs)
+				if signMode == signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON && tt.aminoJSONErr {
+					if err == nil {
+						t.Fatal("expected error, got nil")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("failed to sign and build tx: %s", err)
+				}
+
+				// the tx is decoded with a codec of its own as the node would do
+				codec := raw.MakeCodecDefault()
+				neutrontypes.RegisterInterfaces(codec.InterfaceRegistry)
+				clienttypes.RegisterInterfaces(codec.InterfaceRegistry)
+				txConfig := authtxtypes.NewTxConfig(codec.Marshaller, authtxtypes.DefaultSignModes)
+				decoded, err := txConfig.TxDecoder()(bz)
+				if err != nil {
+					t.Fatalf("failed to decode tx: %s", err)
+				}
+				sigTx, ok := decoded.(authsigning.SigVerifiableTx)
+				if !ok {
+					t.Fatalf("decoded tx %T is not verifiable", decoded)
+				}
+
+				decodedMsgs := sigTx.GetMsgs()
+				if len(decodedMsgs) != len(msgs) {
+					t.Fatalf("expected %d msgs in decoded tx, got %d", len(msgs), len(decodedMsgs))
+				}
+				for i := range msgs {
+					if decodedMsgs[i].String() != msgs[i].String() {
+						t.Fatalf("unexpected msg in decoded tx: %s", decodedMsgs[i])
+					}
+				}
+
+				sigs, err := sigTx.GetSignaturesV2()
+				if err != nil {
+					t.Fatalf("failed to get signatures: %s", err)
+				}
+				if len(sigs) != 1 {
+					t.Fatalf("expected 1 signature, got %d", len(sigs))
+				}
+				sig := sigs[0]
+				if sig.Sequence != 42 {
+					t.Fatalf("expected sequence 42, got %d", sig.Sequence)
+				}
+				if !sig.PubKey.Equals(txs.pubKey) {
+					t.Fatalf("unexpected public key %s", sig.PubKey)
+				}
+				sigData, ok := sig.Data.(*signing.SingleSignatureData)
+				if !ok {
+					t.Fatalf("unexpected signature data %T", sig.Data)
+				}
+				if sigData.SignMode != signMode {
+					t.Fatalf("expected sign mode %s, got %s", signMode, sigData.SignMode)
+				}
+
+				signerData := authsigning.SignerData{ChainID: "neutron-test-1", AccountNumber: 7, Sequence: 42}
+				if err := authsigning.VerifySignature(sig.PubKey, signerData, sigData, txConfig.SignModeHandler(), decoded); err != nil {
+					t.Fatalf("failed to verify signature: %s", err)
+				}
+			})
+		}
+	}
+}
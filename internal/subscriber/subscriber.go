@@ -3,30 +3,42 @@ package subscriber
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	instrumenters "github.com/neutron-org/neutron-query-relayer/internal/metrics"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/rpc/client/http"
 	tmtypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
 	"go.uber.org/zap"
 
 	rg "github.com/neutron-org/neutron-query-relayer/internal/registry"
-	restclient "github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client"
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
 
 var (
 	unsubscribeTimeout = time.Second * 5
+	// txEventsCapacity is the capacity of the submitted query results channels, the events are dropped by the rpc
+	// client when a channel is full. Several results can be submitted in a block, unlike the other events.
+	txEventsCapacity = 100
 )
 
+// reconciliationGraceBlocks is the number of blocks a scheduled KV query result is given to land
+// on chain before the reconciliation considers it lost and reschedules the query.
+const reconciliationGraceBlocks = 10
+
 // SubscriberConfig contains configurable fields for the Subscriber.
 type SubscriberConfig struct {
 	// RPCAddress represents the address for RPC calls to the chain.
 	RPCAddress string
-	// RESTAddress represents the address for REST calls to the chain.
-	RESTAddress string
+	// Querier is used to retrieve registered queries from Neutron.
+	Querier relay.NeutronQuerier
 	// Timeout defines time limit for requests executed by the Subscriber.
 	Timeout time.Duration
 	// ConnectionID is the Neutron's side connection ID used to filter out queries.
@@ -36,6 +48,22 @@ type SubscriberConfig struct {
 	// Registry is a watch list registry. It contains a list of addresses, and the Subscriber only
 	// works with interchain queries and events that are under these addresses' ownership.
 	Registry *rg.Registry
+	// TaskRetryBaseDelay is the number of blocks to wait before retrying a failed task. The delay
+	// is doubled after each consecutive failure, but never exceeds the query update period.
+	TaskRetryBaseDelay uint64
+	// ReconciliationPeriod defines how often the in-memory queries state is reconciled with the
+	// Neutron's one. Zero value disables reconciliation.
+	ReconciliationPeriod time.Duration
+	// SubmitterAddr is the address the relayer submits query results on behalf of (i.e. the relayer's
+	// own address or the authz granter). The results submitted by other addresses are made by competitors.
+	SubmitterAddr string
+	// TxDecoder decodes the query result submission txs to find out the queries they serve.
+	TxDecoder sdk.TxDecoder
+	// Storage persists the query tasks state, so the query schedule survives restarts.
+	Storage relay.Storage
+	// CompetitorLagBlocks makes the relayer a backup for the KV queries: a query is served only if
+	// nobody has submitted its result for this number of blocks after the update period has passed.
+	CompetitorLagBlocks uint64
 }
 
 // NewSubscriber creates a new Subscriber instance ready to subscribe to Neutron events.
@@ -52,12 +80,6 @@ func NewSubscriber(
 		return nil, fmt.Errorf("could not start tendermint rpcClient: %w", err)
 	}
 
-	// restClient is used to retrieve registered queries from Neutron.
-	restClient, err := newRESTClient(cfg.RESTAddress, cfg.Timeout)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get newRESTClient: %w", err)
-	}
-
 	// Contains the types of queries that we are ready to serve (KV / TX).
 	watchedTypesMap := make(map[neutrontypes.InterchainQueryType]struct{})
 	for _, queryType := range cfg.WatchedTypes {
@@ -65,15 +87,23 @@ func NewSubscriber(
 	}
 
 	return &Subscriber{
-		rpcClient:  rpcClient,
-		restClient: restClient,
+		rpcClient: rpcClient,
+		querier:   cfg.Querier,
 
 		connectionID: cfg.ConnectionID,
 		registry:     cfg.Registry,
 		logger:       logger,
 		watchedTypes: watchedTypesMap,
 
+		taskRetryBaseDelay:   cfg.TaskRetryBaseDelay,
+		reconciliationPeriod: cfg.ReconciliationPeriod,
+		submitterAddr:        cfg.SubmitterAddr,
+		txDecoder:            cfg.TxDecoder,
+		competitorLagBlocks:  cfg.CompetitorLagBlocks,
+		storage:              cfg.Storage,
+
 		activeQueries: map[string]*neutrontypes.RegisteredQuery{},
+		taskRetries:   map[string]*taskRetry{},
 	}, nil
 }
 
@@ -81,20 +111,41 @@ func NewSubscriber(
 // filters them in accordance with the Registry configuration and watchedTypes, and provides a
 // stream of split to KV and TX messages.
 type Subscriber struct {
-	rpcClient  *http.HTTP                 // Used to subscribe to events
-	restClient *restclient.HTTPAPIConsole // Used to run Neutron-specific queries using the REST
+	rpcClient *http.HTTP           // Used to subscribe to events
+	querier   relay.NeutronQuerier // Used to run Neutron-specific queries
 
 	connectionID string
 	registry     *rg.Registry
 	logger       *zap.Logger
 	watchedTypes map[neutrontypes.InterchainQueryType]struct{}
 
+	taskRetryBaseDelay   uint64
+	reconciliationPeriod time.Duration
+	submitterAddr        string
+	txDecoder            sdk.TxDecoder
+	competitorLagBlocks  uint64
+	storage              relay.Storage
+
 	activeQueries map[string]*neutrontypes.RegisteredQuery
+	// taskRetries contains retry schedules of the queries whose last task failed.
+	taskRetries map[string]*taskRetry
+	// lastBlockHeight is the height of the last processed Neutron block.
+	lastBlockHeight uint64
+}
+
+// taskRetry describes a retry schedule of a failed task.
+type taskRetry struct {
+	// failures is the number of consecutive failures of the query tasks.
+	failures uint64
+	// height is the Neutron height to retry the task at. Zero means the retry is already queued.
+	height uint64
 }
 
-// Subscribe subscribes to 3 types of events: 1. a new block was created, 2. a query was updated (created / updated),
-// 3. a query was removed.
-func (s *Subscriber) Subscribe(ctx context.Context, tasks chan neutrontypes.RegisteredQuery) error {
+// Subscribe subscribes to 5 types of events: 1. a new block was created, 2. a query was updated (created / updated),
+// 3. a query was removed, 4. a query result was submitted, 5. a query result was submitted with an authz MsgExec.
+// 5 subscriptions is the tendermint's default max_subscriptions_per_client, so any new one requires the Neutron
+// node config to be changed (or a websocket connection of its own).
+func (s *Subscriber) Subscribe(ctx context.Context, tasks chan neutrontypes.RegisteredQuery, tasksFeedback <-chan relay.TaskFeedback) error {
 	queries, err := s.getNeutronRegisteredQueries(ctx)
 	if err != nil {
 		return fmt.Errorf("could not getNeutronRegisteredQueries: %w", err)
@@ -102,6 +153,10 @@ func (s *Subscriber) Subscribe(ctx context.Context, tasks chan neutrontypes.Regi
 	s.activeQueries = queries
 	instrumenters.SetQueriesToProcessNumElements(len(s.activeQueries))
 
+	if err := s.restoreTasks(); err != nil {
+		return fmt.Errorf("failed to restoreTasks: %w", err)
+	}
+
 	// Make sure we try to unsubscribe from events if an error occurs.
 	defer s.unsubscribe()
 
@@ -120,11 +175,35 @@ func (s *Subscriber) Subscribe(ctx context.Context, tasks chan neutrontypes.Regi
 		return fmt.Errorf("could not subscribe to events: %w", err)
 	}
 
+	submitEvents, err := s.rpcClient.Subscribe(ctx, s.subscriberName(), s.getQueryResultSubmittedSubscription(), txEventsCapacity)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to events: %w", err)
+	}
+
+	execEvents, err := s.rpcClient.Subscribe(ctx, s.subscriberName(), s.getAuthzExecSubscription(), txEventsCapacity)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to events: %w", err)
+	}
+
+	// a nil channel blocks forever, i.e. reconciliation is disabled
+	var reconciliation <-chan time.Time
+	if s.reconciliationPeriod > 0 {
+		ticker := time.NewTicker(s.reconciliationPeriod)
+		defer ticker.Stop()
+		reconciliation = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			s.logger.Info("Context cancelled, shutting down subscriber...")
 			return nil
+		case feedback := <-tasksFeedback:
+			s.processTaskFeedback(feedback)
+		case <-reconciliation:
+			if err := s.reconcile(ctx); err != nil {
+				s.logger.Error("failed to reconcile queries with Neutron", zap.Error(err))
+			}
 		case event := <-blockEvents:
 			s.logger.Debug("new block event", zap.String("query", event.Query))
 			if err := s.processBlockEvent(ctx, tasks); err != nil {
@@ -140,6 +219,10 @@ func (s *Subscriber) Subscribe(ctx context.Context, tasks chan neutrontypes.Regi
 			if err = s.processRemoveEvent(event); err != nil {
 				return fmt.Errorf("failed to processRemoveEvent: %w", err)
 			}
+		case event := <-submitEvents:
+			s.processSubmitEvent(event)
+		case event := <-execEvents:
+			s.processSubmitEvent(event)
 		}
 	}
 }
@@ -151,20 +234,232 @@ func (s *Subscriber) processBlockEvent(ctx context.Context, tasks chan neutronty
 		return fmt.Errorf("failed to get Status: %w", err)
 	}
 	currentHeight := uint64(status.SyncInfo.LatestBlockHeight)
+	s.lastBlockHeight = currentHeight
 
-	for _, activeQuery := range s.activeQueries {
-		// Skip the ActiveQuery if we didn't reach the update time.
-		if currentHeight < (activeQuery.LastSubmittedResultLocalHeight + activeQuery.UpdatePeriod) {
+	for queryID, activeQuery := range s.activeQueries {
+		updateHeight := activeQuery.LastSubmittedResultLocalHeight + activeQuery.UpdatePeriod
+		if neutrontypes.InterchainQueryType(activeQuery.QueryType).IsKV() {
+			updateHeight += s.competitorLagBlocks
+		}
+		// Skip the ActiveQuery if we didn't reach neither the update time nor the failed task retry time.
+		if currentHeight < updateHeight && !s.isRetryDue(queryID, currentHeight) {
 			continue
 		}
 
+		// Persist the task before sending it, so the relayer's status updates are never overwritten.
+		s.saveTask(queryID, relay.TaskQueued, currentHeight, s.failures(queryID))
+
 		// Send the query to the tasks queue.
 		tasks <- *activeQuery
 		instrumenters.SetSubscriberTaskQueueNumElements(len(tasks))
 
-		// Set the LastSubmittedResultLocalHeight to the current height.
+		// Set the LastSubmittedResultLocalHeight to the current height. If the task fails, it is
+		// rescheduled on the relayer's feedback (see processTaskFeedback).
 		activeQuery.LastSubmittedResultLocalHeight = currentHeight
+		if retry, ok := s.taskRetries[queryID]; ok {
+			retry.height = 0
+		}
+	}
+
+	return nil
+}
+
+// restoreTasks restores the query schedule persisted before the restart:
+//   - the scheduling height of the done tasks is kept, so the queries are not served again before their
+//     update period passes (e.g. the TX queries that had no new txs and thus no result submitted);
+//   - the queued, in-flight and failed tasks are interrupted, so they are rerun on the next block.
+//
+// The tasks of the queries not registered anymore are removed.
+func (s *Subscriber) restoreTasks() error {
+	tasks, err := s.storage.GetAllQueryTasks()
+	if err != nil {
+		return fmt.Errorf("failed to GetAllQueryTasks: %w", err)
+	}
+
+	var interrupted int
+	for _, task := range tasks {
+		queryID := strconv.FormatUint(task.QueryID, 10)
+		activeQuery, ok := s.activeQueries[queryID]
+		if !ok {
+			if err := s.storage.RemoveQueryTask(task.QueryID); err != nil {
+				s.logger.Error("failed to remove task of unknown query", zap.String("query_id", queryID), zap.Error(err))
+			}
+			continue
+		}
+
+		if task.ScheduledHeight > activeQuery.LastSubmittedResultLocalHeight {
+			activeQuery.LastSubmittedResultLocalHeight = task.ScheduledHeight
+		}
+		if task.Status != relay.TaskDone {
+			// the retry at height 1 is due on the next block
+			s.taskRetries[queryID] = &taskRetry{failures: task.Failures, height: 1}
+			interrupted++
+		}
+	}
+	s.logger.Info("restored query tasks", zap.Int("tasks", len(tasks)), zap.Int("interrupted", interrupted))
+
+	return nil
+}
+
+// saveTask persists the state of the latest task of the query, a failure to persist it only affects
+// the schedule after a restart, so it's logged only.
+func (s *Subscriber) saveTask(queryID string, status relay.QueryTaskStatus, scheduledHeight uint64, failures uint64) {
+	id, err := strconv.ParseUint(queryID, 10, 64)
+	if err != nil {
+		s.logger.Error("failed to parse query id", zap.String("query_id", queryID), zap.Error(err))
+		return
+	}
+
+	err = s.storage.SetQueryTask(&relay.QueryTask{
+		QueryID:         id,
+		Status:          status,
+		ScheduledHeight: scheduledHeight,
+		Failures:        failures,
+		UpdatedAt:       time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("failed to save query task", zap.String("query_id", queryID), zap.Error(err))
+	}
+}
+
+// removeTask removes the persisted task state of the removed query.
+func (s *Subscriber) removeTask(queryID string) {
+	id, err := strconv.ParseUint(queryID, 10, 64)
+	if err != nil {
+		s.logger.Error("failed to parse query id", zap.String("query_id", queryID), zap.Error(err))
+		return
+	}
+
+	if err := s.storage.RemoveQueryTask(id); err != nil {
+		s.logger.Error("failed to remove query task", zap.String("query_id", queryID), zap.Error(err))
+	}
+}
+
+// failures returns the number of consecutive failures of the query tasks.
+func (s *Subscriber) failures(queryID string) uint64 {
+	if retry, ok := s.taskRetries[queryID]; ok {
+		return retry.failures
+	}
+	return 0
+}
+
+// isRetryDue returns true if the query's last task failed and it's time to retry it.
+func (s *Subscriber) isRetryDue(queryID string, currentHeight uint64) bool {
+	retry, ok := s.taskRetries[queryID]
+	return ok && retry.height != 0 && currentHeight >= retry.height
+}
+
+// processTaskFeedback reschedules the failed query tasks with an exponential backoff and resets
+// the backoff on a successful one. The paused tasks are rescheduled without backing off.
+func (s *Subscriber) processTaskFeedback(feedback relay.TaskFeedback) {
+	queryID := strconv.FormatUint(feedback.QueryID, 10)
+	activeQuery, ok := s.activeQueries[queryID]
+	if !ok {
+		return
+	}
+
+	if feedback.Success {
+		delete(s.taskRetries, queryID)
+		s.saveTask(queryID, relay.TaskDone, activeQuery.LastSubmittedResultLocalHeight, 0)
+		return
+	}
+
+	retry, ok := s.taskRetries[queryID]
+	if !ok {
+		retry = &taskRetry{}
+		s.taskRetries[queryID] = retry
+	}
+
+	// the paused task is retried after the base delay and keeps its failures count
+	if feedback.Paused {
+		delay := s.taskRetryBaseDelay
+		if activeQuery.UpdatePeriod < delay {
+			delay = activeQuery.UpdatePeriod
+		}
+		retry.height = s.lastBlockHeight + delay
+		s.saveTask(queryID, relay.TaskQueued, activeQuery.LastSubmittedResultLocalHeight, retry.failures)
+
+		s.logger.Debug("task paused, rescheduled", zap.String("query_id", queryID),
+			zap.Uint64("retry_height", retry.height))
+		return
+	}
+
+	retry.failures++
+
+	delay := activeQuery.UpdatePeriod
+	// avoid overflow on shifting: the delay is capped by the update period anyway
+	if retry.failures <= 32 {
+		if backoff := s.taskRetryBaseDelay << (retry.failures - 1); backoff < delay {
+			delay = backoff
+		}
+	}
+	retry.height = s.lastBlockHeight + delay
+	s.saveTask(queryID, relay.TaskFailed, activeQuery.LastSubmittedResultLocalHeight, retry.failures)
+
+	s.logger.Debug("task failed, rescheduled", zap.String("query_id", queryID),
+		zap.Uint64("failures", retry.failures), zap.Uint64("retry_height", retry.height))
+}
+
+// reconcile fetches the registered queries from Neutron and fixes the in-memory state, so the missed
+// query events don't leave it wrong until restart:
+//   - the queries missing in memory are added, and the ones removed on Neutron are deleted;
+//   - the queries whose parameters differ are replaced with the Neutron's ones.
+//
+// The in-memory LastSubmittedResultLocalHeight is the height a query task was scheduled at, so:
+//   - if Neutron has a newer height, the result was submitted by someone else, and we catch up with it;
+//   - if a KV query result scheduled long enough ago never landed on chain (e.g. the tx failed in
+//     DeliverTx or the task feedback was lost), the query is rescheduled at the on-chain height.
+//
+// TX queries results are not submitted if no new txs are found, so they are never rescheduled.
+func (s *Subscriber) reconcile(ctx context.Context) error {
+	queries, err := s.getNeutronRegisteredQueries(ctx)
+	if err != nil {
+		return fmt.Errorf("could not getNeutronRegisteredQueries: %w", err)
+	}
+
+	for queryID := range s.activeQueries {
+		if _, ok := queries[queryID]; !ok {
+			s.logger.Info("query removal was missed, removing", zap.String("query_id", queryID))
+			delete(s.activeQueries, queryID)
+			delete(s.taskRetries, queryID)
+			s.removeTask(queryID)
+			instrumenters.IncQueriesDriftRemoved()
+		}
+	}
+
+	for queryID, remoteQuery := range queries {
+		activeQuery, ok := s.activeQueries[queryID]
+		if !ok {
+			s.logger.Info("query registration was missed, adding", zap.String("query_id", queryID))
+			s.activeQueries[queryID] = remoteQuery
+			instrumenters.IncQueriesDriftAdded()
+			continue
+		}
+		if !equalQueryParams(activeQuery, remoteQuery) {
+			s.logger.Info("query update was missed, updating", zap.String("query_id", queryID))
+			// the in-memory scheduling height is kept and reconciled below
+			updatedQuery := *remoteQuery
+			updatedQuery.LastSubmittedResultLocalHeight = activeQuery.LastSubmittedResultLocalHeight
+			activeQuery = &updatedQuery
+			s.activeQueries[queryID] = activeQuery
+			instrumenters.IncQueriesDriftUpdated()
+		}
+
+		var (
+			remoteHeight = remoteQuery.LastSubmittedResultLocalHeight
+			localHeight  = activeQuery.LastSubmittedResultLocalHeight
+		)
+		switch {
+		case remoteHeight > localHeight:
+			activeQuery.LastSubmittedResultLocalHeight = remoteHeight
+		case remoteHeight < localHeight && neutrontypes.InterchainQueryType(activeQuery.QueryType).IsKV() &&
+			s.lastBlockHeight >= localHeight+reconciliationGraceBlocks:
+			s.logger.Info("query result has not landed on chain, rescheduling", zap.String("query_id", queryID),
+				zap.Uint64("scheduled_height", localHeight), zap.Uint64("remote_height", remoteHeight))
+			activeQuery.LastSubmittedResultLocalHeight = remoteHeight
+		}
 	}
+	instrumenters.SetQueriesToProcessNumElements(len(s.activeQueries))
 
 	return nil
 }
@@ -235,6 +530,8 @@ func (s *Subscriber) processRemoveEvent(event tmtypes.ResultEvent) error {
 
 		// Delete the query from the active queries list.
 		delete(s.activeQueries, queryID)
+		delete(s.taskRetries, queryID)
+		s.removeTask(queryID)
 		instrumenters.SetQueriesToProcessNumElements(len(s.activeQueries))
 		s.logger.Debug("Query removed", zap.String("query_id", queryID), zap.Int("total_queries_number", len(s.activeQueries)))
 	}
@@ -242,6 +539,76 @@ func (s *Subscriber) processRemoveEvent(event tmtypes.ResultEvent) error {
 	return nil
 }
 
+// processSubmitEvent counts the query results accepted by Neutron and backs off the KV queries served by
+// the competing relayers, so the relayer doesn't submit a result someone else has just submitted.
+func (s *Subscriber) processSubmitEvent(event tmtypes.ResultEvent) {
+	data, ok := event.Data.(types.EventDataTx)
+	if !ok {
+		s.logger.Error("unexpected tx event data type", zap.String("type", fmt.Sprintf("%T", event.Data)))
+		return
+	}
+	// the queries aren't updated by the failed txs
+	if data.Result.Code != abci.CodeTypeOK {
+		return
+	}
+
+	tx, err := s.txDecoder(data.Tx)
+	if err != nil {
+		// e.g. a MsgExec of the messages unknown to the relayer
+		s.logger.Debug("failed to decode tx", zap.Error(err))
+		return
+	}
+
+	for _, msg := range submitQueryResultMsgs(tx.GetMsgs()) {
+		s.processQueryResultSubmission(msg, uint64(data.Height))
+	}
+}
+
+func (s *Subscriber) processQueryResultSubmission(msg *neutrontypes.MsgSubmitQueryResult, height uint64) {
+	queryID := strconv.FormatUint(msg.QueryId, 10)
+	activeQuery, ok := s.activeQueries[queryID]
+	if !ok {
+		return
+	}
+
+	if msg.Sender == s.submitterAddr {
+		instrumenters.IncQuerySubmissionWon(activeQuery.QueryType)
+		return
+	}
+	instrumenters.IncQuerySubmissionLost(activeQuery.QueryType)
+
+	// TX queries results are submitted per found tx, one submitted by a competitor doesn't mean the rest are
+	if !neutrontypes.InterchainQueryType(activeQuery.QueryType).IsKV() {
+		return
+	}
+	if height > activeQuery.LastSubmittedResultLocalHeight {
+		activeQuery.LastSubmittedResultLocalHeight = height
+		delete(s.taskRetries, queryID)
+		s.logger.Debug("query result submitted by another relayer", zap.String("query_id", queryID),
+			zap.String("sender", msg.Sender), zap.Uint64("height", height))
+	}
+}
+
+// submitQueryResultMsgs returns the query result submissions among the msgs, including the ones executed
+// with authz
+func submitQueryResultMsgs(msgs []sdk.Msg) []*neutrontypes.MsgSubmitQueryResult {
+	var out []*neutrontypes.MsgSubmitQueryResult
+	for _, msg := range msgs {
+		switch m := msg.(type) {
+		case *neutrontypes.MsgSubmitQueryResult:
+			out = append(out, m)
+		case *authz.MsgExec:
+			innerMsgs, err := m.GetMessages()
+			if err != nil {
+				continue
+			}
+			out = append(out, submitQueryResultMsgs(innerMsgs)...)
+		}
+	}
+
+	return out
+}
+
 // unsubscribes from all previously registered subscriptions. Please note that
 // this method does not return an error and does not panic.
 func (s *Subscriber) unsubscribe() {
@@ -254,6 +621,8 @@ func (s *Subscriber) unsubscribe() {
 			s.getQueryUpdatedSubscription(),
 			s.getQueryRemovedSubscription(),
 			s.getNewBlockHeaderSubscription(),
+			s.getQueryResultSubmittedSubscription(),
+			s.getAuthzExecSubscription(),
 		}
 	)
 	for _, subscription := range subscriptions {
@@ -1,29 +1,24 @@
 package subscriber
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"net/http"
-	"net/url"
+	"strconv"
 	"time"
 
-	httptransport "github.com/go-openapi/runtime/client"
-	"github.com/go-openapi/strfmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
 	tmtypes "github.com/tendermint/tendermint/rpc/core/types"
 	jsonrpcclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
 	"github.com/tendermint/tendermint/types"
 	"go.uber.org/zap"
 
-	restclient "github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client"
-	"github.com/neutron-org/neutron-query-relayer/internal/subscriber/querier/client/query"
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
 
-var (
-	restClientBasePath = "/"
-	rpcWSEndpoint      = "/websocket"
-)
+var rpcWSEndpoint = "/websocket"
 
 // newRPCClient creates a new tendermint RPC client with timeout.
 func newRPCClient(rpcAddr string, timeout time.Duration) (*tmhttp.HTTP, error) {
@@ -35,73 +30,33 @@ func newRPCClient(rpcAddr string, timeout time.Duration) (*tmhttp.HTTP, error) {
 	return tmhttp.NewWithClient(rpcAddr, rpcWSEndpoint, httpClient)
 }
 
-// newRESTClient makes sure that the restAddr is formed correctly and returns a REST query.
-func newRESTClient(restAddr string, timeout time.Duration) (*restclient.HTTPAPIConsole, error) {
-	url, err := url.Parse(restAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse restAddr: %w", err)
-	}
-
-	httpClient := http.DefaultClient
-	httpClient.Timeout = timeout
-	transport := httptransport.NewWithClient(url.Host, restClientBasePath, []string{url.Scheme}, httpClient)
-
-	return restclient.New(transport, nil), nil
-}
-
 // getNeutronRegisteredQuery retrieves a registered query from Neutron.
 func (s *Subscriber) getNeutronRegisteredQuery(ctx context.Context, queryId string) (*neutrontypes.RegisteredQuery, error) {
-	res, err := s.restClient.Query.NeutronInterchainQueriesRegisteredQuery(
-		&query.NeutronInterchainQueriesRegisteredQueryParams{
-			QueryID: &queryId,
-			Context: ctx,
-		},
-	)
+	id, err := strconv.ParseUint(queryId, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get NeutronInterchainqueriesRegisteredQuery: %w", err)
+		return nil, fmt.Errorf("failed to parse query id %s: %w", queryId, err)
 	}
-	neutronQuery, err := res.GetPayload().RegisteredQuery.ToNeutronRegisteredQuery()
+	neutronQuery, err := s.querier.RegisteredQuery(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get neutronQueryFromRestQuery: %w", err)
+		return nil, fmt.Errorf("failed to get RegisteredQuery: %w", err)
 	}
 	return neutronQuery, nil
 }
 
 // getNeutronRegisteredQueries retrieves the list of registered queries filtered by owner, connection, and query type.
 func (s *Subscriber) getNeutronRegisteredQueries(ctx context.Context) (map[string]*neutrontypes.RegisteredQuery, error) {
-	var out = map[string]*neutrontypes.RegisteredQuery{}
-	var pageKey *strfmt.Base64
-	for {
-		res, err := s.restClient.Query.NeutronInterchainQueriesRegisteredQueries(
-			&query.NeutronInterchainQueriesRegisteredQueriesParams{
-				Owners:        s.registry.GetAddresses(),
-				ConnectionID:  &s.connectionID,
-				Context:       ctx,
-				PaginationKey: pageKey,
-			},
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get NeutronInterchainqueriesRegisteredQueries: %w", err)
-		}
-
-		payload := res.GetPayload()
-
-		for _, restQuery := range payload.RegisteredQueries {
-			neutronQuery, err := restQuery.ToNeutronRegisteredQuery()
-			if err != nil {
-				return nil, fmt.Errorf("failed to cast ToNeutronRegisteredQuery: %w", err)
-			}
+	queries, err := s.querier.RegisteredQueries(ctx, s.registry.GetAddresses(), s.connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RegisteredQueries: %w", err)
+	}
 
-			if !s.isWatchedMsgType(neutronQuery.QueryType) {
-				continue
-			}
-			out[restQuery.ID] = neutronQuery
-		}
-		if payload.Pagination != nil && payload.Pagination.NextKey.String() != "" {
-			pageKey = &payload.Pagination.NextKey
-		} else {
-			break
+	var out = map[string]*neutrontypes.RegisteredQuery{}
+	for idx := range queries {
+		neutronQuery := &queries[idx]
+		if !s.isWatchedMsgType(neutronQuery.QueryType) {
+			continue
 		}
+		out[strconv.FormatUint(neutronQuery.Id, 10)] = neutronQuery
 	}
 	s.logger.Debug("total queries fetched", zap.Int("queries number", len(out)))
 
@@ -161,6 +116,41 @@ func (s *Subscriber) getNewBlockHeaderSubscription() string {
 	)
 }
 
+// getQueryResultSubmittedSubscription returns a Query to filter out the txs submitting interchain query results
+// directly, i.e. not with an authz MsgExec.
+func (s *Subscriber) getQueryResultSubmittedSubscription() string {
+	return fmt.Sprintf("%s='%s' AND %s.%s='%s'",
+		eventAttr, types.EventTx,
+		sdk.EventTypeMessage, sdk.AttributeKeyAction, sdk.MsgTypeURL(&neutrontypes.MsgSubmitQueryResult{}),
+	)
+}
+
+// getAuthzExecSubscription returns a Query to filter out the authz MsgExec txs, which might submit interchain
+// query results on behalf of the granters.
+func (s *Subscriber) getAuthzExecSubscription() string {
+	return fmt.Sprintf("%s='%s' AND %s.%s='%s'",
+		eventAttr, types.EventTx,
+		sdk.EventTypeMessage, sdk.AttributeKeyAction, sdk.MsgTypeURL(&authz.MsgExec{}),
+	)
+}
+
+// equalQueryParams returns true if the queries have the same parameters, the results submission state
+// is not compared.
+func equalQueryParams(a, b *neutrontypes.RegisteredQuery) bool {
+	if a.Owner != b.Owner || a.QueryType != b.QueryType || a.ConnectionId != b.ConnectionId ||
+		a.UpdatePeriod != b.UpdatePeriod || a.TransactionsFilter != b.TransactionsFilter ||
+		len(a.Keys) != len(b.Keys) {
+		return false
+	}
+	for i := range a.Keys {
+		if a.Keys[i].Path != b.Keys[i].Path || !bytes.Equal(a.Keys[i].Key, b.Keys[i].Key) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // isWatchedMsgType returns true if the given message type was added to the subscriber's watched
 // ActiveQuery types list.
 func (s *Subscriber) isWatchedMsgType(msgType string) bool {
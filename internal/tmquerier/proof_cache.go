@@ -0,0 +1,112 @@
+package tmquerier
+
+import (
+	"context"
+	"sync"
+
+	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+)
+
+// proofCacheKey identifies a single proof: a key in a store at a height
+type proofCacheKey struct {
+	storeKey string
+	key      string
+	height   int64
+}
+
+// proofCacheEntry is a cached (or being fetched) proof. done is closed once the fetching is finished.
+type proofCacheEntry struct {
+	done        chan struct{}
+	value       *neutrontypes.StorageValue
+	proofHeight uint64
+	err         error
+}
+
+// proofCache is a height-scoped cache of ABCI proofs. It keeps proofs for the `heights` most recent
+// heights only and coalesces concurrent requests for the same proof into a single ABCI query.
+type proofCache struct {
+	mu        sync.Mutex
+	heights   int64
+	maxHeight int64
+	entries   map[proofCacheKey]*proofCacheEntry
+	hits      uint64
+	total     uint64
+}
+
+func newProofCache(heights uint64) *proofCache {
+	return &proofCache{
+		heights: int64(heights),
+		entries: map[proofCacheKey]*proofCacheEntry{},
+	}
+}
+
+// get returns the proof for the key from the cache. If there is no such proof in the cache, it is
+// fetched with fetch and cached. If the proof is being fetched by someone else at the moment, get
+// waits for the result instead of making one more request. Failed requests are not cached.
+func (c *proofCache) get(
+	ctx context.Context,
+	key proofCacheKey,
+	fetch func() (*neutrontypes.StorageValue, uint64, error),
+) (*neutrontypes.StorageValue, uint64, error) {
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.recordLookup(found)
+	if !found {
+		entry = &proofCacheEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+		c.evict(key.height)
+	}
+	c.mu.Unlock()
+
+	if !found {
+		entry.value, entry.proofHeight, entry.err = fetch()
+		close(entry.done)
+		if entry.err != nil {
+			c.mu.Lock()
+			if c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+	}
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+	if entry.err != nil {
+		return nil, 0, entry.err
+	}
+
+	// return a copy so that callers can't modify the cached value
+	value := *entry.value
+	return &value, entry.proofHeight, nil
+}
+
+// evict removes proofs that are older than the `heights` most recent heights. Must be called under the lock.
+func (c *proofCache) evict(height int64) {
+	if height <= c.maxHeight {
+		return
+	}
+	c.maxHeight = height
+
+	for key := range c.entries {
+		if key.height <= c.maxHeight-c.heights {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// recordLookup updates the cache hit ratio metrics. Must be called under the lock.
+func (c *proofCache) recordLookup(hit bool) {
+	c.total++
+	if hit {
+		c.hits++
+		neutronmetrics.IncKVProofCacheHit()
+	} else {
+		neutronmetrics.IncKVProofCacheMiss()
+	}
+	neutronmetrics.SetKVProofCacheHitRatio(float64(c.hits) / float64(c.total))
+}
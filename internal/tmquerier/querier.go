@@ -3,12 +3,15 @@ package tmquerier
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	abci "github.com/tendermint/tendermint/abci/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	rpcclienthttp "github.com/tendermint/tendermint/rpc/client/http"
 
+	"github.com/neutron-org/neutron-query-relayer/internal/quorum"
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
 
@@ -17,11 +20,63 @@ type Querier struct {
 	Client  *rpcclienthttp.HTTP
 	ChainID string
 	cdc     codec.LegacyAmino
+	// quorum is used to cross-check the Client responses with other target chain nodes. Cross-checks
+	// are disabled if nil.
+	quorum *quorum.Checker
+	// proofCache is used to share proofs between queries with overlapping keys. Caching is disabled if nil.
+	proofCache *proofCache
+
+	// heightAlignmentWindow is the period during which LatestHeight returns the same height.
+	heightAlignmentWindow time.Duration
+	heightMu              sync.Mutex
+	alignedHeight         int64
+	alignedAt             time.Time
 }
 
-func NewQuerier(client *rpcclienthttp.HTTP, chainId string) (*Querier, error) {
+// NewQuerier creates a new Querier. proofCacheHeights is the number of the most recent heights to
+// cache proofs for (0 disables the cache), and heightAlignmentWindow is the period during which the
+// same latest height is returned to align queries processed in the same block (0 disables alignment).
+func NewQuerier(
+	client *rpcclienthttp.HTTP,
+	chainId string,
+	quorumChecker *quorum.Checker,
+	proofCacheHeights uint64,
+	heightAlignmentWindow time.Duration,
+) (*Querier, error) {
 	legacyCdc := codec.NewLegacyAmino()
-	return &Querier{Client: client, ChainID: chainId, cdc: *legacyCdc}, nil
+	q := &Querier{
+		Client:                client,
+		ChainID:               chainId,
+		cdc:                   *legacyCdc,
+		quorum:                quorumChecker,
+		heightAlignmentWindow: heightAlignmentWindow,
+	}
+	if proofCacheHeights > 0 {
+		q.proofCache = newProofCache(proofCacheHeights)
+	}
+
+	return q, nil
+}
+
+// LatestHeight returns the latest height of the chain. All calls made within the heightAlignmentWindow
+// after the height is fetched return the same height, so the queries that fall due in the same block
+// are processed on a common height and can share proofs.
+func (q *Querier) LatestHeight(ctx context.Context) (int64, error) {
+	q.heightMu.Lock()
+	defer q.heightMu.Unlock()
+
+	if q.heightAlignmentWindow > 0 && time.Since(q.alignedAt) < q.heightAlignmentWindow {
+		return q.alignedHeight, nil
+	}
+
+	status, err := q.Client.Status(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	q.alignedHeight = status.SyncInfo.LatestBlockHeight
+	q.alignedAt = time.Now()
+	return q.alignedHeight, nil
 }
 
 // QueryTendermintProof performs an ABCI query with the given key and returns
@@ -48,6 +103,19 @@ func (q *Querier) QueryTendermintProof(ctx context.Context, height int64, storeK
 		height--
 	}
 
+	// the latest state changes from block to block, so there is nothing to share
+	if q.proofCache == nil || height == 0 {
+		return q.queryTendermintProof(ctx, height, storeKey, key)
+	}
+
+	return q.proofCache.get(ctx, proofCacheKey{storeKey: storeKey, key: string(key), height: height},
+		func() (*neutrontypes.StorageValue, uint64, error) {
+			return q.queryTendermintProof(ctx, height, storeKey, key)
+		})
+}
+
+// queryTendermintProof performs an ABCI query with the given key at the given IAVL height.
+func (q *Querier) queryTendermintProof(ctx context.Context, height int64, storeKey string, key []byte) (*neutrontypes.StorageValue, uint64, error) {
 	req := abci.RequestQuery{
 		Path:   fmt.Sprintf("store/%s/key", storeKey),
 		Height: height,
@@ -65,6 +133,12 @@ func (q *Querier) QueryTendermintProof(ctx context.Context, height int64, storeK
 		return nil, 0, fmt.Errorf("error making abci query for tendermint proof: %w", err)
 	}
 
+	if q.quorum != nil {
+		if err := q.quorum.CheckABCIQuery(ctx, req.Path, req.Data, opts, res.Response); err != nil {
+			return nil, 0, fmt.Errorf("failed to cross-check abci query for tendermint proof: %w", err)
+		}
+	}
+
 	response := res.Response
 	return &neutrontypes.StorageValue{Value: response.Value, Key: key, Proof: response.ProofOps, StoragePrefix: storeKey}, uint64(response.Height + 1), nil
 }
@@ -3,6 +3,7 @@ package trusted_headers
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cosmos/relayer/v2/relayer/chains/cosmos"
@@ -37,20 +38,68 @@ var (
 // - included in the block (inclusion proof)
 // - successfully executed (delivery proof)
 type TrustedHeaderFetcher struct {
-	neutronChain   *relayer.Chain
-	targetChain    *relayer.Chain
-	logger         *zap.Logger
-	revisionNumber uint64
+	neutronChain *relayer.Chain
+	logger       *zap.Logger
+
+	// revisions are the target chain revisions ordered by their start heights. The headers of a revision
+	// can only be fetched with the chain provider of the revision chain ID.
+	revisionsMu sync.RWMutex
+	revisions   []targetRevision
+}
+
+// targetRevision is the target chain ID serving the heights starting from startHeight
+type targetRevision struct {
+	number      uint64
+	startHeight uint64
+	chain       *relayer.Chain
 }
 
 // NewTrustedHeaderFetcher constructs a new TrustedHeaderFetcher
 func NewTrustedHeaderFetcher(neutronChain *relayer.Chain, targetChain *relayer.Chain, logger *zap.Logger) *TrustedHeaderFetcher {
 	return &TrustedHeaderFetcher{
-		neutronChain:   neutronChain,
-		targetChain:    targetChain,
-		logger:         logger,
-		revisionNumber: clienttypes.ParseChainID(targetChain.ChainID()),
+		neutronChain: neutronChain,
+		logger:       logger,
+		revisions: []targetRevision{{
+			number: clienttypes.ParseChainID(targetChain.ChainID()),
+			chain:  targetChain,
+		}},
+	}
+}
+
+// AddRevision implements relay.TargetRevisionTracker. If the startHeight is not above the start height of the
+// latest revision, the target chain heights have been restarted and the previous revisions are dropped since
+// their heights can't be told from the new ones.
+func (thf *TrustedHeaderFetcher) AddRevision(chain *relayer.Chain, startHeight uint64) {
+	thf.revisionsMu.Lock()
+	defer thf.revisionsMu.Unlock()
+
+	revision := targetRevision{
+		number:      clienttypes.ParseChainID(chain.ChainID()),
+		startHeight: startHeight,
+		chain:       chain,
+	}
+	if startHeight <= thf.revisions[len(thf.revisions)-1].startHeight {
+		thf.revisions = []targetRevision{revision}
+	} else {
+		thf.revisions = append(thf.revisions, revision)
 	}
+	thf.logger.Info("added target chain revision",
+		zap.String("chain_id", chain.ChainID()),
+		zap.Uint64("revision", revision.number),
+		zap.Uint64("start_height", startHeight))
+}
+
+// revisionAt returns the target chain revision the height belongs to
+func (thf *TrustedHeaderFetcher) revisionAt(height uint64) targetRevision {
+	thf.revisionsMu.RLock()
+	defer thf.revisionsMu.RUnlock()
+
+	for i := len(thf.revisions) - 1; i > 0; i-- {
+		if height >= thf.revisions[i].startHeight {
+			return thf.revisions[i]
+		}
+	}
+	return thf.revisions[0]
 }
 
 // FetchTrustedHeaderForHeight returns the best suitable TrustedHeader for given height
@@ -59,15 +108,19 @@ func NewTrustedHeaderFetcher(neutronChain *relayer.Chain, targetChain *relayer.C
 func (thf *TrustedHeaderFetcher) Fetch(ctx context.Context, height uint64) (header ibcexported.Header, err error) {
 	start := time.Now()
 
+	// the header and the trusted consensus state have to be of the same revision for the client update
+	revision := thf.revisionAt(height)
+
 	// tries to find height of the closest consensus state height that is less or equal than provided height
-	trustedHeight, err := thf.getTrustedHeight(ctx, height)
+	trustedHeight, err := thf.getTrustedHeight(ctx, revision.number, height)
 	if err != nil {
 		err = fmt.Errorf("no satisfying consensus state found: %w", err)
 		return
 	}
-	thf.logger.Debug("Found suitable consensus state with trusted height", zap.Uint64("height", trustedHeight.RevisionHeight))
+	thf.logger.Debug("Found suitable consensus state with trusted height", zap.Uint64("height", trustedHeight.RevisionHeight),
+		zap.Uint64("revision", trustedHeight.RevisionNumber))
 
-	header, err = thf.trustedHeaderAtHeight(ctx, trustedHeight, height)
+	header, err = thf.trustedHeaderAtHeight(ctx, revision.chain, trustedHeight, height)
 	if err != nil {
 		err = fmt.Errorf("failed to get header for src chain: %w", err)
 		return
@@ -89,10 +142,11 @@ func (thf *TrustedHeaderFetcher) Fetch(ctx context.Context, height uint64) (head
 // This allows us to send UpdateClient msg not only for new heights, but for the old ones (which are still in the trusting period).
 //
 // Arguments:
+// `targetChain` - target chain of the revision the height belongs to
 // `trustedHeight` - height of any consensus state that's height < supplied height
 // `height` - remote chain height for a header
-func (thf *TrustedHeaderFetcher) trustedHeaderAtHeight(ctx context.Context, trustedHeight *clienttypes.Height, height uint64) (ibcexported.Header, error) {
-	header, err := thf.retryGetLightSignedHeaderAtHeight(ctx, height)
+func (thf *TrustedHeaderFetcher) trustedHeaderAtHeight(ctx context.Context, targetChain *relayer.Chain, trustedHeight *clienttypes.Height, height uint64) (ibcexported.Header, error) {
+	header, err := thf.retryGetLightSignedHeaderAtHeight(ctx, targetChain, height)
 	if err != nil {
 		return nil, fmt.Errorf("could not get light header: %w", err)
 	}
@@ -100,7 +154,7 @@ func (thf *TrustedHeaderFetcher) trustedHeaderAtHeight(ctx context.Context, trus
 	// NOTE: We need to get validators from the source chain at height: trustedHeight+1
 	// since the last trusted validators for a header at height h is the NextValidators
 	// at h+1 committed to in header h by NextValidatorsHash
-	nextHeader, err := thf.retryGetLightSignedHeaderAtHeight(ctx, trustedHeight.RevisionHeight+1)
+	nextHeader, err := thf.retryGetLightSignedHeaderAtHeight(ctx, targetChain, trustedHeight.RevisionHeight+1)
 	if err != nil {
 		return nil, fmt.Errorf("could not get next light header: %w", err)
 	}
@@ -117,8 +171,9 @@ func (thf *TrustedHeaderFetcher) trustedHeaderAtHeight(ctx context.Context, trus
 // Note that we cannot optimize this search due to consensus states being stored in a tree with *STRING* key `RevisionNumber-RevisionHeight`
 //
 // Arguments:
+// `revision` - found consensus state will be of this revision
 // `height` - found consensus state will be with a height <= than it
-func (thf *TrustedHeaderFetcher) getTrustedHeight(ctx context.Context, height uint64) (*clienttypes.Height, error) {
+func (thf *TrustedHeaderFetcher) getTrustedHeight(ctx context.Context, revision uint64, height uint64) (*clienttypes.Height, error) {
 	// Without this hack it doesn't want to work with NewQueryClient
 	neutronProvider, ok := thf.neutronChain.ChainProvider.(*cosmos.CosmosProvider)
 	if !ok {
@@ -142,7 +197,7 @@ func (thf *TrustedHeaderFetcher) getTrustedHeight(ctx context.Context, height ui
 		}
 
 		for _, cs := range page.ConsensusStates {
-			suitable, err := thf.isSuitableCS(cs, trustingPeriod, height)
+			suitable, err := thf.isSuitableCS(cs, revision, trustingPeriod, height)
 			if err != nil {
 				return nil, err
 			} else if suitable {
@@ -157,7 +212,7 @@ func (thf *TrustedHeaderFetcher) getTrustedHeight(ctx context.Context, height ui
 		}
 	}
 
-	return nil, fmt.Errorf("could not find any trusted consensus state for height=%d", height)
+	return nil, fmt.Errorf("could not find any trusted consensus state for height=%d in revision=%d", height, revision)
 }
 
 // fetchTrustingPeriod fetches trusting period of the client
@@ -178,11 +233,11 @@ func (thf *TrustedHeaderFetcher) fetchTrustingPeriod(ctx context.Context) (time.
 	return tmClientState.TrustingPeriod, nil
 }
 
-func (thf *TrustedHeaderFetcher) retryGetLightSignedHeaderAtHeight(ctx context.Context, height uint64) (*tmclient.Header, error) {
+func (thf *TrustedHeaderFetcher) retryGetLightSignedHeaderAtHeight(ctx context.Context, targetChain *relayer.Chain, height uint64) (*tmclient.Header, error) {
 	var tmHeader *tmclient.Header
 
 	if err := retry.Do(func() error {
-		header, err := thf.targetChain.ChainProvider.GetLightSignedHeaderAtHeight(ctx, int64(height))
+		header, err := targetChain.ChainProvider.GetLightSignedHeaderAtHeight(ctx, int64(height))
 		if err != nil {
 			return err
 		}
@@ -207,14 +262,14 @@ func (thf *TrustedHeaderFetcher) retryGetLightSignedHeaderAtHeight(ctx context.C
 // isSuitableCS parses the given consensus state and checks if its height can be used as trusted.
 // The condition for this check is the following:
 //
-// 1. The consensus state height is in the same revision and is less than the given height;
+// 1. The consensus state height is in the given revision and is less than the given height;
 // 2. The consensus state timestamp is within the trusting period.
-func (thf *TrustedHeaderFetcher) isSuitableCS(cs clienttypes.ConsensusStateWithHeight, trustingPeriod time.Duration, height uint64) (bool, error) {
+func (thf *TrustedHeaderFetcher) isSuitableCS(cs clienttypes.ConsensusStateWithHeight, revision uint64, trustingPeriod time.Duration, height uint64) (bool, error) {
 	ibcCS, ok := cs.ConsensusState.GetCachedValue().(ibcexported.ConsensusState)
 	if !ok {
 		return false, fmt.Errorf("couldn't cast consensus state value of type %T to ibcexported.ConsensusState", cs.ConsensusState.GetCachedValue())
 	}
-	olderThanHeightInSameRevision := cs.Height.RevisionNumber == thf.revisionNumber && cs.Height.RevisionHeight < height
+	olderThanHeightInSameRevision := cs.Height.RevisionNumber == revision && cs.Height.RevisionHeight < height
 	consensusTimestamp := time.Unix(0, int64(ibcCS.GetTimestamp()))
 	inTrustingPeriod := consensusTimestamp.Add(trustingPeriod).Add(-submissionMarginPeriod).After(time.Now())
 	return olderThanHeightInSameRevision && inTrustingPeriod, nil
@@ -4,28 +4,29 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
-	"regexp"
 	"time"
 
 	neutronmetrics "github.com/neutron-org/neutron-query-relayer/internal/metrics"
 
-	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	tmtypes "github.com/tendermint/tendermint/types"
 	"go.uber.org/zap"
 
 	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/relay"
 	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
 )
 
 type TXProcessor struct {
-	trustedHeaderFetcher        relay.TrustedHeaderFetcher
-	storage                     relay.Storage
-	submitter                   relay.Submitter
-	logger                      *zap.Logger
-	checkSubmittedTxStatusDelay time.Duration
-	ignoreErrorsRegexp          *regexp.Regexp
+	trustedHeaderFetcher relay.TrustedHeaderFetcher
+	storage              relay.Storage
+	submitter            relay.Submitter
+	logger               *zap.Logger
+	// errorClassifier decides how to handle the submission errors, unknown errors are critical
+	errorClassifier relay.ErrorClassifier
+	// proofVerifier is used to verify proofs locally before submission. Verification is disabled if nil.
+	proofVerifier relay.ProofVerifier
 }
 
 func NewTxProcessor(
@@ -33,16 +34,16 @@ func NewTxProcessor(
 	storage relay.Storage,
 	submitter relay.Submitter,
 	logger *zap.Logger,
-	checkSubmittedTxStatusDelay time.Duration,
-	ignoreErrorsRegexp string,
+	errorClassifier relay.ErrorClassifier,
+	proofVerifier relay.ProofVerifier,
 ) TXProcessor {
 	txProcessor := TXProcessor{
-		trustedHeaderFetcher:        trustedHeaderFetcher,
-		storage:                     storage,
-		submitter:                   submitter,
-		logger:                      logger,
-		checkSubmittedTxStatusDelay: checkSubmittedTxStatusDelay,
-		ignoreErrorsRegexp:          regexp.MustCompile(ignoreErrorsRegexp),
+		trustedHeaderFetcher: trustedHeaderFetcher,
+		storage:              storage,
+		submitter:            submitter,
+		logger:               logger,
+		errorClassifier:      errorClassifier,
+		proofVerifier:        proofVerifier,
 	}
 
 	return txProcessor
@@ -104,7 +105,7 @@ func (r *TXProcessor) processSuccessfulTxSubmission(
 		return fmt.Errorf("failed to store tx submit status: %w", err)
 	}
 
-	go r.delayedTxStatusCheck(ctx, relay.PendingSubmittedTxInfo{
+	r.trackSubmittedTx(ctx, relay.PendingSubmittedTxInfo{
 		QueryID:         queryID,
 		SubmittedTxHash: hash,
 		NeutronHash:     neutronTxHash,
@@ -115,8 +116,9 @@ func (r *TXProcessor) processSuccessfulTxSubmission(
 	return nil
 }
 
-// processFailedTxSubmission checks whether the error is ignored. If it's ignored, it stores the tx status in the
-// storage; otherwise it escalates the error.
+// processFailedTxSubmission handles the error according to its class. Ignored errors are stored as the tx status
+// in the storage, retryable ones are returned for the tx to be submitted again on the next query processing,
+// the rest are escalated.
 func (r *TXProcessor) processFailedTxSubmission(
 	err error,
 	queryID uint64,
@@ -128,8 +130,11 @@ func (r *TXProcessor) processFailedTxSubmission(
 	neutronmetrics.AddFailedProof(string(neutrontypes.InterchainQueryTypeTX), time.Since(proofStart).Seconds())
 	r.logger.Error("could not submit proof", zap.Error(err), zap.Uint64("query_id", queryID))
 
-	// check error with regexp
-	if !r.ignoreErrorsRegexp.MatchString(err.Error()) {
+	switch r.errorClassifier.Classify(err) {
+	case relay.ErrorClassIgnorable:
+	case relay.ErrorClassRetryable:
+		return fmt.Errorf("could not submit proof (retryable): %w", err)
+	default:
 		return relay.NewErrSubmitTxProofCritical(err)
 	}
 
@@ -142,30 +147,38 @@ func (r *TXProcessor) processFailedTxSubmission(
 	return nil
 }
 
-// We submit the PendingSubmittedTxInfo only after checkSubmittedTxStatusDelay to reduce the possibility of
-// unsuccessful checks (the block is 100% not ready here yet).
-func (r TXProcessor) delayedTxStatusCheck(ctx context.Context, tx relay.PendingSubmittedTxInfo, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo,
-) {
-	var t = time.NewTimer(r.checkSubmittedTxStatusDelay)
+// trackSubmittedTx hands the submitted tx over to the TxSubmitChecker, which resolves it as soon as
+// its block is committed.
+func (r TXProcessor) trackSubmittedTx(ctx context.Context, tx relay.PendingSubmittedTxInfo, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) {
 	select {
-	case <-t.C:
-		submittedTxsTasksQueue <- relay.PendingSubmittedTxInfo{
-			QueryID:         tx.QueryID,
-			SubmittedTxHash: tx.SubmittedTxHash,
-			NeutronHash:     tx.NeutronHash,
-		}
+	case submittedTxsTasksQueue <- tx:
 	case <-ctx.Done():
-		r.logger.Info("Cancelled PendingSubmittedTxInfo delayed checking",
+		r.logger.Info("Cancelled PendingSubmittedTxInfo tracking",
 			zap.Uint64("query_id", tx.QueryID),
 			zap.String("submitted_tx_hash", tx.SubmittedTxHash))
 	}
 }
 
 func (r TXProcessor) txToBlock(ctx context.Context, tx relay.Transaction) (*neutrontypes.Block, error) {
-	packedHeader, packedNextHeader, err := r.prepareHeaders(ctx, tx)
+	header, nextHeader, err := r.prepareHeaders(ctx, tx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare headers: %w", err)
 	}
+
+	if err = r.verifyProof(header, nextHeader, tx); err != nil {
+		return nil, fmt.Errorf("failed to verify tx proof: %w", err)
+	}
+
+	packedHeader, err := clienttypes.PackHeader(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack header: %w", err)
+	}
+
+	packedNextHeader, err := clienttypes.PackHeader(nextHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack next header: %w", err)
+	}
+
 	block := neutrontypes.Block{
 		Header:          packedHeader,
 		NextBlockHeader: packedNextHeader,
@@ -174,7 +187,25 @@ func (r TXProcessor) txToBlock(ctx context.Context, tx relay.Transaction) (*neut
 	return &block, nil
 }
 
-// prepareHeaders returns two Headers for height and height+1 packed into *codectypes.Any value
+// verifyProof checks the tx inclusion and delivery proofs against the trusted headers we are about
+// to submit. Does nothing if the local proof verification is disabled.
+func (r TXProcessor) verifyProof(header ibcexported.Header, nextHeader ibcexported.Header, tx relay.Transaction) error {
+	if r.proofVerifier == nil {
+		return nil
+	}
+
+	if err := r.proofVerifier.VerifyTxProof(header, nextHeader, tx.Tx); err != nil {
+		neutronmetrics.IncFailedProofVerification(string(neutrontypes.InterchainQueryTypeTX))
+		r.logger.Error("proof verification failed, rejecting tx",
+			zap.String("hash", hex.EncodeToString(tmtypes.Tx(tx.Tx.Data).Hash())), zap.Uint64("height", tx.Height), zap.Error(err))
+		return err
+	}
+	neutronmetrics.IncSuccessProofVerification(string(neutrontypes.InterchainQueryTypeTX))
+
+	return nil
+}
+
+// prepareHeaders returns two Headers for height and height+1
 // We need two blocks in Neutron to verify both delivery of tx and inclusion in block:
 // - We need to know block X (`header`) to verify inclusion of transaction for block X (inclusion proof)
 // - We need to know block X+1 (`nextHeader`) to verify response of transaction for block X
@@ -183,33 +214,17 @@ func (r TXProcessor) txToBlock(ctx context.Context, tx relay.Transaction) (*neut
 // Arguments:
 // `txStruct` - Transaction that represents single tx with height
 func (r TXProcessor) prepareHeaders(ctx context.Context, txStruct relay.Transaction) (
-	packedHeader *codectypes.Any, packedNextHeader *codectypes.Any, err error) {
+	header ibcexported.Header, nextHeader ibcexported.Header, err error) {
 
-	packedHeader, err = r.getTrustedHeader(ctx, txStruct.Height)
+	header, err = r.trustedHeaderFetcher.Fetch(ctx, txStruct.Height)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get header with trusted height: %w", err)
 	}
 
-	packedNextHeader, err = r.getTrustedHeader(ctx, txStruct.Height+1)
+	nextHeader, err = r.trustedHeaderFetcher.Fetch(ctx, txStruct.Height+1)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get next header with trusted height: %w", err)
 	}
 
 	return
 }
-
-func (r TXProcessor) getTrustedHeader(ctx context.Context, height uint64) (
-	packedHeader *codectypes.Any, err error) {
-
-	header, err := r.trustedHeaderFetcher.Fetch(ctx, height)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get header with trusted height: %w", err)
-	}
-
-	packedHeader, err = clienttypes.PackHeader(header)
-	if err != nil {
-		return nil, fmt.Errorf("failed to pack header: %w", err)
-	}
-
-	return
-}
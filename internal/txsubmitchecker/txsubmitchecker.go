@@ -1,17 +1,22 @@
 package txsubmitchecker
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	instrumenters "github.com/neutron-org/neutron-query-relayer/internal/metrics"
 
 	"github.com/avast/retry-go/v4"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	abci "github.com/tendermint/tendermint/abci/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 	"go.uber.org/zap"
 
 	"github.com/neutron-org/neutron-query-relayer/internal/relay"
@@ -22,49 +27,123 @@ var (
 	retryDelay     = retry.Delay(1 * time.Second)
 	retryError     = retry.LastErrorOnly(false)
 	requestTimeout = 10 * time.Second
+	// mempoolScanLimit is the max number of mempool txs we look through to find a submitted tx,
+	// it's the max page size of the unconfirmed_txs rpc method
+	mempoolScanLimit = 100
+	// subscriberName is the name the tx events are subscribed to on the neutron node with
+	subscriberName = "neutron-tx-submit-checker"
+	// txEventsCapacity is the capacity of the tx events channel, the events are dropped by the rpc client
+	// when the channel is full
+	txEventsCapacity = 100
+	// maxEarlyResults limits the number of committed txs which are not handed over by the processors yet
+	maxEarlyResults = 1000
 )
 
+// TxSubmitChecker tracks the submitted txs until they are committed. The txs are confirmed by the neutron
+// websocket events of the txs signed by the relayer, the pending txs are polled periodically to catch the events
+// missed e.g. while the websocket reconnects. The txs evicted from the mempool are rebuilt from the cached data
+// and rebroadcast, the txs not committed within the confirmation deadline are moved to the unsuccessful queue
+// as Expired.
 type TxSubmitChecker struct {
 	storage   relay.Storage
 	rpcClient rpcclient.Client
 	logger    *zap.Logger
+	// senderAddr is the address the relayer signs the txs with, the tx events are filtered by it
+	senderAddr string
+	// gasObserver learns the gas usage of the committed transactions, nil if not needed
+	gasObserver relay.GasObserver
+	// txProcessor and kvProcessor rebroadcast the evicted txs
+	txProcessor relay.TXProcessor
+	kvProcessor relay.KVProcessor
+	// deadlineBlocks is the number of neutron blocks a submission has to be committed within
+	deadlineBlocks uint64
+	// pollPeriod is the period the pending txs are checked with
+	pollPeriod time.Duration
+
+	// pending contains the txs being tracked by their neutron hashes
+	pending map[string]relay.PendingSubmittedTxInfo
+	// trackedSince contains the times the pending txs are tracked since by their neutron hashes, a tx is polled
+	// only if it's not confirmed by an event within a poll period
+	trackedSince map[string]time.Time
+	// earlyResults contains the results of the committed txs which are not handed over by the processors yet
+	// by their neutron hashes, a tx might be committed before it gets to the queue
+	earlyResults map[string]abci.ResponseDeliverTx
+	// suspectedEvictions contains the neutron hashes of the txs which were found neither in the mempool nor
+	// in a block on the last check. A tx is rebroadcast only if it's missing on two checks in a row since
+	// the committed txs are indexed with a delay.
+	suspectedEvictions map[string]struct{}
 }
 
 func NewTxSubmitChecker(
 	storage relay.Storage,
 	rpcClient rpcclient.Client,
 	logger *zap.Logger,
+	senderAddr string,
+	gasObserver relay.GasObserver,
+	txProcessor relay.TXProcessor,
+	kvProcessor relay.KVProcessor,
+	deadlineBlocks uint64,
+	pollPeriod time.Duration,
 ) *TxSubmitChecker {
 	return &TxSubmitChecker{
-		storage:   storage,
-		rpcClient: rpcClient,
-		logger:    logger,
+		storage:            storage,
+		rpcClient:          rpcClient,
+		logger:             logger,
+		senderAddr:         senderAddr,
+		gasObserver:        gasObserver,
+		txProcessor:        txProcessor,
+		kvProcessor:        kvProcessor,
+		deadlineBlocks:     deadlineBlocks,
+		pollPeriod:         pollPeriod,
+		pending:            make(map[string]relay.PendingSubmittedTxInfo),
+		trackedSince:       make(map[string]time.Time),
+		earlyResults:       make(map[string]abci.ResponseDeliverTx),
+		suspectedEvictions: make(map[string]struct{}),
 	}
 }
 
-func (tc *TxSubmitChecker) Run(ctx context.Context, submittedTxsTasksQueue <-chan relay.PendingSubmittedTxInfo) error {
+func (tc *TxSubmitChecker) Run(ctx context.Context, submittedTxsTasksQueue chan relay.PendingSubmittedTxInfo) error {
 	// Read and process all pending submitted transactions on startup.
 	pending, err := tc.storage.GetAllPendingTxs()
 	if err != nil {
 		return fmt.Errorf("failed to read pending txs from storage: %w", err)
 	}
 
+	// Make sure we try to unsubscribe from events if an error occurs.
+	defer tc.unsubscribe()
+
+	// the events are subscribed to before the pending txs are polled not to miss the txs committed in between
+	txEvents := tc.subscribe(ctx)
+
 	for _, tx := range pending {
-		if err := tc.processSubmittedTx(ctx, tx); err != nil {
-			tc.logger.Error("Failed to processSubmittedTx (on startup)",
-				zap.Error(err), zap.String("tx_neutron_hash", tx.NeutronHash),
-				zap.String("tx_submitted_hash", tx.SubmittedTxHash))
-		}
+		tc.track(ctx, *tx)
 	}
+	tc.checkPendingTxs(ctx, time.Now())
+
+	ticker := time.NewTicker(tc.pollPeriod)
+	defer ticker.Stop()
 
 	for {
 		select {
+		case event, ok := <-txEvents:
+			if !ok {
+				tc.logger.Error("tx events subscription is closed, falling back to polling until resubscribed")
+				txEvents = nil
+				continue
+			}
+			tc.processTxEvent(event)
 		case tx := <-submittedTxsTasksQueue:
-			if err := tc.processSubmittedTx(ctx, &tx); err != nil {
-				tc.logger.Error("Failed to processSubmittedTx",
-					zap.Error(err), zap.String("tx_neutron_hash", tx.NeutronHash),
-					zap.String("tx_submitted_hash", tx.SubmittedTxHash))
+			tc.track(ctx, tx)
+		case <-ticker.C:
+			if txEvents == nil {
+				txEvents = tc.subscribe(ctx)
+				// the txs committed while there was no subscription are caught up with right away
+				if txEvents != nil {
+					tc.checkPendingTxs(ctx, time.Now())
+					continue
+				}
 			}
+			tc.checkPendingTxs(ctx, time.Now().Add(-tc.pollPeriod))
 		case <-ctx.Done():
 			tc.logger.Info("Context cancelled, shutting down TxSubmitChecker...")
 			return nil
@@ -72,18 +151,164 @@ func (tc *TxSubmitChecker) Run(ctx context.Context, submittedTxsTasksQueue <-cha
 	}
 }
 
-func (tc *TxSubmitChecker) processSubmittedTx(ctx context.Context, tx *relay.PendingSubmittedTxInfo) error {
+// subscribe subscribes to the committed txs sent by the relayer, it returns nil if the subscription fails
+// so the pending txs are confirmed by polling only
+func (tc *TxSubmitChecker) subscribe(ctx context.Context) <-chan coretypes.ResultEvent {
+	txEvents, err := tc.rpcClient.Subscribe(ctx, subscriberName, tc.txSubscription(), txEventsCapacity)
+	if err != nil {
+		tc.logger.Error("failed to subscribe to tx events, falling back to polling", zap.Error(err))
+		return nil
+	}
+
+	return txEvents
+}
+
+func (tc *TxSubmitChecker) unsubscribe() {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := tc.rpcClient.Unsubscribe(ctx, subscriberName, tc.txSubscription()); err != nil {
+		tc.logger.Error("failed to Unsubscribe from tx events", zap.Error(err))
+	}
+}
+
+// txSubscription returns a Query to filter out the txs signed by the relayer. The ante handler sets the tx.acc_seq
+// attribute to "<signer>/<sequence>" for every signer, the relayer signs both direct and authz submissions.
+func (tc *TxSubmitChecker) txSubscription() string {
+	return fmt.Sprintf("%s='%s' AND %s.%s CONTAINS '%s/'",
+		tmtypes.EventTypeKey, tmtypes.EventTx,
+		sdk.EventTypeTx, sdk.AttributeKeyAccountSequence, tc.senderAddr,
+	)
+}
+
+// processTxEvent resolves the pending tx committed in the event, the result is kept until the tx is handed over
+// by a processor if it's not tracked yet
+func (tc *TxSubmitChecker) processTxEvent(event coretypes.ResultEvent) {
+	data, ok := event.Data.(tmtypes.EventDataTx)
+	if !ok {
+		tc.logger.Error("unexpected tx event data type", zap.String("type", fmt.Sprintf("%T", event.Data)))
+		return
+	}
+
+	neutronHash := hex.EncodeToString(tmtypes.Tx(data.Tx).Hash())
+	tx, ok := tc.pending[neutronHash]
+	if !ok {
+		if len(tc.earlyResults) >= maxEarlyResults {
+			tc.earlyResults = make(map[string]abci.ResponseDeliverTx)
+		}
+		tc.earlyResults[neutronHash] = data.Result
+		return
+	}
+
+	tc.logger.Debug("submitted tx is committed", zap.String("neutron_hash", neutronHash), zap.Int64("height", data.Height))
+	tc.processTxResult(&tx, data.Result)
+	tc.untrack(tx.NeutronHash)
+}
+
+// track starts tracking the tx, it's resolved right away if it's already committed. The submission height
+// of a new tx is recorded right away for the confirmation deadline to count from it.
+func (tc *TxSubmitChecker) track(ctx context.Context, tx relay.PendingSubmittedTxInfo) {
+	if result, ok := tc.earlyResults[tx.NeutronHash]; ok {
+		delete(tc.earlyResults, tx.NeutronHash)
+		tc.processTxResult(&tx, result)
+		return
+	}
+
+	tc.pending[tx.NeutronHash] = tx
+	tc.trackedSince[tx.NeutronHash] = time.Now()
+
+	if tx.SubmittedHeight == 0 {
+		latestHeight, err := tc.latestHeight(ctx)
+		if err != nil {
+			// the height is recorded on the first poll of the tx then
+			tc.logger.Error("failed to get submission height of tx",
+				zap.String("neutron_hash", tx.NeutronHash), zap.Error(err))
+			return
+		}
+		tx.SubmittedHeight = latestHeight
+		tc.updatePendingTx(tx)
+	}
+}
+
+// updatePendingTx updates the tracking info of the pending tx both in memory and in the storage
+func (tc *TxSubmitChecker) updatePendingTx(tx relay.PendingSubmittedTxInfo) {
+	if _, ok := tc.pending[tx.NeutronHash]; ok {
+		tc.pending[tx.NeutronHash] = tx
+	}
+	if err := tc.storage.UpdatePendingTx(tx); err != nil {
+		tc.logger.Error("failed to update pending tx in storage",
+			zap.String("neutron_hash", tx.NeutronHash), zap.Error(err))
+	}
+}
+
+func (tc *TxSubmitChecker) latestHeight(ctx context.Context) (uint64, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	status, err := tc.rpcClient.Status(timeoutCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(status.SyncInfo.LatestBlockHeight), nil
+}
+
+func (tc *TxSubmitChecker) untrack(neutronHash string) {
+	delete(tc.pending, neutronHash)
+	delete(tc.trackedSince, neutronHash)
+	delete(tc.suspectedEvictions, neutronHash)
+}
+
+// checkPendingTxs polls the status of the pending txs tracked since before the trackedBefore time
+func (tc *TxSubmitChecker) checkPendingTxs(ctx context.Context, trackedBefore time.Time) {
+	for _, tx := range tc.pending {
+		if tc.trackedSince[tx.NeutronHash].After(trackedBefore) {
+			continue
+		}
+		tc.checkPendingTx(ctx, tx)
+	}
+}
+
+func (tc *TxSubmitChecker) checkPendingTx(ctx context.Context, tx relay.PendingSubmittedTxInfo) {
+	done, err := tc.processSubmittedTx(ctx, &tx)
+	if err != nil {
+		tc.logger.Error("Failed to processSubmittedTx",
+			zap.Error(err), zap.String("tx_neutron_hash", tx.NeutronHash),
+			zap.String("tx_submitted_hash", tx.SubmittedTxHash))
+	}
+	if done {
+		tc.untrack(tx.NeutronHash)
+	}
+}
+
+// processSubmittedTx checks the tx status and returns true if the tx doesn't need to be tracked anymore
+func (tc *TxSubmitChecker) processSubmittedTx(ctx context.Context, tx *relay.PendingSubmittedTxInfo) (bool, error) {
 	neutronHash, err := hex.DecodeString(tx.NeutronHash)
 	if err != nil {
-		return fmt.Errorf("failed to DecodeString: %w", err)
+		// the tx can never be found
+		return true, fmt.Errorf("failed to DecodeString: %w", err)
 	}
 
-	txResponse, err := tc.retryGetTxStatus(ctx, neutronHash)
+	txResponse, found, err := tc.retryGetTxStatus(ctx, neutronHash)
 	if err != nil {
-		return fmt.Errorf("failed to retryGetTxStatus: %w", err)
+		return false, fmt.Errorf("failed to retryGetTxStatus: %w", err)
+	}
+	if !found {
+		return tc.processNotFoundTx(ctx, tx, neutronHash)
+	}
+	tc.processTxResult(tx, txResponse.TxResult)
+
+	return true, nil
+}
+
+// processTxResult stores the result of the committed tx
+func (tc *TxSubmitChecker) processTxResult(tx *relay.PendingSubmittedTxInfo, result abci.ResponseDeliverTx) {
+	if tc.gasObserver != nil {
+		outOfGas := result.Codespace == sdkerrors.RootCodespace && result.Code == sdkerrors.ErrOutOfGas.ABCICode()
+		tc.gasObserver.ObserveTxResult(tx.NeutronHash, result.GasUsed, outOfGas)
 	}
 
-	if txResponse.TxResult.Code == abci.CodeTypeOK {
+	if result.Code == abci.CodeTypeOK {
 		instrumenters.IncSuccessTxSubmit()
 		tc.updateTxStatus(tx, relay.SubmittedTxInfo{
 			Status: relay.Committed,
@@ -92,17 +317,127 @@ func (tc *TxSubmitChecker) processSubmittedTx(ctx context.Context, tx *relay.Pen
 		instrumenters.IncFailedTxSubmit()
 		tc.updateTxStatus(tx, relay.SubmittedTxInfo{
 			Status:  relay.ErrorOnCommit,
-			Message: fmt.Sprintf("Code: %d, Log: %s", txResponse.TxResult.Code, txResponse.TxResult.Log),
+			Message: fmt.Sprintf("Code: %d, Log: %s", result.Code, result.Log),
+		})
+	}
+}
+
+// processNotFoundTx expires the tx if its deadline has passed, or rebroadcasts it if it's been evicted
+// from the mempool. It returns true if the tx doesn't need to be tracked anymore.
+func (tc *TxSubmitChecker) processNotFoundTx(ctx context.Context, tx *relay.PendingSubmittedTxInfo, neutronHash []byte) (bool, error) {
+	latestHeight, err := tc.latestHeight(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get neutron chain status: %w", err)
+	}
+
+	if tx.SubmittedHeight == 0 {
+		tx.SubmittedHeight = latestHeight
+		tc.updatePendingTx(*tx)
+	}
+	deadline := tx.SubmittedHeight + tc.deadlineBlocks
+	if latestHeight >= deadline {
+		instrumenters.IncExpiredTxSubmit()
+		tc.updateTxStatus(tx, relay.SubmittedTxInfo{
+			Status:  relay.Expired,
+			Message: fmt.Sprintf("not committed before height %d", deadline),
 		})
+		return true, nil
+	}
+
+	inMempool, known, err := tc.isInMempool(ctx, neutronHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mempool: %w", err)
+	}
+	if !known {
+		// the tx is not rebroadcast blindly, it's expired on the deadline if it's evicted indeed
+		tc.logger.Debug("mempool is too large to look through, eviction of tx is unknown",
+			zap.String("neutron_hash", tx.NeutronHash))
+		return false, nil
+	}
+	if inMempool {
+		delete(tc.suspectedEvictions, tx.NeutronHash)
+		return false, nil
+	}
+	if _, ok := tc.suspectedEvictions[tx.NeutronHash]; !ok {
+		tc.suspectedEvictions[tx.NeutronHash] = struct{}{}
+		return false, nil
+	}
+
+	tc.logger.Info("submitted tx is evicted from the mempool, rebroadcasting",
+		zap.Uint64("query_id", tx.QueryID),
+		zap.String("query_type", tx.QueryType),
+		zap.String("neutron_hash", tx.NeutronHash),
+		zap.Uint64("deadline", deadline))
+	if err := tc.rebroadcast(ctx, tx); err != nil {
+		return false, fmt.Errorf("failed to rebroadcast evicted tx: %w", err)
+	}
+	instrumenters.IncRebroadcastTxSubmit()
+
+	// the rebroadcast tx replaces the evicted one in the pending queue
+	if err := tc.storage.RemovePendingTx(tx.NeutronHash); err != nil {
+		return true, fmt.Errorf("failed to remove evicted tx from pending queue: %w", err)
 	}
 
-	return nil
+	return true, nil
 }
 
+// rebroadcast rebuilds the submission of the tx from the cached data and submits it again with a fresh sequence.
+// The rebroadcast tx is tracked right away instead of being handed over through the tasks queue, which is
+// drained by the checker itself.
+func (tc *TxSubmitChecker) rebroadcast(ctx context.Context, tx *relay.PendingSubmittedTxInfo) error {
+	rebroadcastQueue := make(chan relay.PendingSubmittedTxInfo, 1)
+	defer func() {
+		select {
+		case rebroadcastTx := <-rebroadcastQueue:
+			// the confirmation deadline keeps counting from the original submission
+			rebroadcastTx.SubmittedHeight = tx.SubmittedHeight
+			tc.track(ctx, rebroadcastTx)
+			tc.updatePendingTx(rebroadcastTx)
+		default:
+		}
+	}()
+
+	if tx.IsKV() {
+		msg, err := tc.storage.GetCachedKV(tx.QueryID)
+		if err != nil {
+			return fmt.Errorf("failed to get cached kv: %w", err)
+		}
+		// fresh values and proofs are fetched since Neutron doesn't accept results older than the last submitted one
+		return tc.kvProcessor.Rebroadcast(ctx, msg, rebroadcastQueue)
+	}
+
+	cachedTx, err := tc.storage.GetCachedTx(tx.QueryID, tx.SubmittedTxHash)
+	if err != nil {
+		return fmt.Errorf("failed to get cached tx: %w", err)
+	}
+	return tc.txProcessor.ProcessAndSubmit(ctx, tx.QueryID, *cachedTx, rebroadcastQueue)
+}
+
+// isInMempool returns whether the tx is in the mempool of the node. The second value is false if the presence
+// is unknown, i.e. the tx is not found but the mempool is too large to look through.
+func (tc *TxSubmitChecker) isInMempool(ctx context.Context, neutronHash []byte) (bool, bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	res, err := tc.rpcClient.UnconfirmedTxs(timeoutCtx, &mempoolScanLimit)
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, mempoolTx := range res.Txs {
+		if bytes.Equal(tmtypes.Tx(mempoolTx).Hash(), neutronHash) {
+			return true, true, nil
+		}
+	}
+
+	return false, res.Total <= res.Count, nil
+}
+
+// retryGetTxStatus returns the tx result, or false if the tx is not found (i.e. it's not committed yet)
 func (tc *TxSubmitChecker) retryGetTxStatus(
 	ctx context.Context,
 	neutronHash []byte,
-) (*coretypes.ResultTx, error) {
+) (*coretypes.ResultTx, bool, error) {
 	var result *coretypes.ResultTx
 	if err := retry.Do(func() error {
 		timeoutCtx, cancel := context.WithTimeout(ctx, requestTimeout)
@@ -113,15 +448,29 @@ func (tc *TxSubmitChecker) retryGetTxStatus(
 			return err
 		}
 		return nil
-	}, retry.Context(ctx), retryAttempts, retryDelay, retryError); err != nil {
-		return nil, err
+	}, retry.Context(ctx), retryAttempts, retryDelay, retryError, retry.RetryIf(func(err error) bool {
+		return !isTxNotFound(err)
+	})); err != nil {
+		if isTxNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
 	}
 
-	return result, nil
+	return result, true, nil
+}
+
+func isTxNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
 }
 
 func (tc *TxSubmitChecker) updateTxStatus(tx *relay.PendingSubmittedTxInfo, status relay.SubmittedTxInfo) {
-	err := tc.storage.SetTxStatus(tx.QueryID, tx.SubmittedTxHash, tx.NeutronHash, status, nil)
+	var err error
+	if tx.IsKV() {
+		err = tc.storage.SetKVStatus(tx.QueryID, tx.NeutronHash, status, nil)
+	} else {
+		err = tc.storage.SetTxStatus(tx.QueryID, tx.SubmittedTxHash, tx.NeutronHash, status, nil)
+	}
 	if err != nil {
 		tc.logger.Error(
 			"failed to update tx status in storage",
@@ -131,6 +480,8 @@ func (tc *TxSubmitChecker) updateTxStatus(tx *relay.PendingSubmittedTxInfo, stat
 	} else {
 		tc.logger.Info(
 			"set tx status",
+			zap.Uint64("query_id", tx.QueryID),
+			zap.String("query_type", tx.QueryType),
 			zap.String("neutron_hash", tx.NeutronHash),
 			zap.String("status", string(status.Status)),
 		)
@@ -0,0 +1,306 @@
+package txsubmitchecker
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	neutrontypes "github.com/neutron-org/neutron/x/interchainqueries/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"go.uber.org/zap"
+
+	"github.com/neutron-org/neutron-query-relayer/internal/relay"
+)
+
+// statusStorage records the statuses set by the checker and the changes of the pending queue
+type statusStorage struct {
+	relay.Storage
+	statuses map[string]relay.SubmittedTxInfo
+	pending  map[string]relay.PendingSubmittedTxInfo
+	removed  map[string]struct{}
+}
+
+func newStatusStorage() *statusStorage {
+	return &statusStorage{
+		statuses: make(map[string]relay.SubmittedTxInfo),
+		pending:  make(map[string]relay.PendingSubmittedTxInfo),
+		removed:  make(map[string]struct{}),
+	}
+}
+
+func (s *statusStorage) SetTxStatus(_ uint64, _ string, neutronHash string, status relay.SubmittedTxInfo, _ *relay.Transaction) error {
+	s.statuses[neutronHash] = status
+	return nil
+}
+
+func (s *statusStorage) SetKVStatus(_ uint64, neutronHash string, status relay.SubmittedTxInfo, _ *relay.MessageKV) error {
+	s.statuses[neutronHash] = status
+	return nil
+}
+
+func (s *statusStorage) UpdatePendingTx(txInfo relay.PendingSubmittedTxInfo) error {
+	s.pending[txInfo.NeutronHash] = txInfo
+	return nil
+}
+
+func (s *statusStorage) RemovePendingTx(neutronHash string) error {
+	delete(s.pending, neutronHash)
+	s.removed[neutronHash] = struct{}{}
+	return nil
+}
+
+func (s *statusStorage) GetCachedKV(queryID uint64) (*relay.MessageKV, error) {
+	return &relay.MessageKV{QueryId: queryID}, nil
+}
+
+func (s *statusStorage) GetCachedTx(_ uint64, _ string) (*relay.Transaction, error) {
+	return &relay.Transaction{Height: 1}, nil
+}
+
+// chainClient is a neutron rpc client with no committed txs and a fixed mempool
+type chainClient struct {
+	rpcclient.Client
+	height  int64
+	mempool []tmtypes.Tx
+	// mempoolTotal is the number of txs in the mempool, the mempool is fully listed if it's zero
+	mempoolTotal int
+}
+
+func (c *chainClient) Tx(_ context.Context, hash []byte, _ bool) (*coretypes.ResultTx, error) {
+	return nil, fmt.Errorf("tx (%X) not found", hash)
+}
+
+func (c *chainClient) Status(_ context.Context) (*coretypes.ResultStatus, error) {
+	return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: c.height}}, nil
+}
+
+func (c *chainClient) UnconfirmedTxs(_ context.Context, _ *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	total := c.mempoolTotal
+	if total == 0 {
+		total = len(c.mempool)
+	}
+	return &coretypes.ResultUnconfirmedTxs{Count: len(c.mempool), Total: total, Txs: c.mempool}, nil
+}
+
+// resubmitter resubmits the txs under the new hashes of "<query id>-<resubmission number>"
+type resubmitter struct {
+	resubmissions int
+}
+
+func (r *resubmitter) resubmit(queryID uint64, queryType string, queue chan relay.PendingSubmittedTxInfo) {
+	r.resubmissions++
+	queue <- relay.PendingSubmittedTxInfo{
+		QueryID:     queryID,
+		QueryType:   queryType,
+		NeutronHash: hex.EncodeToString([]byte(fmt.Sprintf("%d-%d", queryID, r.resubmissions))),
+	}
+}
+
+type txResubmitter struct {
+	*resubmitter
+}
+
+func (p txResubmitter) ProcessAndSubmit(_ context.Context, queryID uint64, _ relay.Transaction, queue chan relay.PendingSubmittedTxInfo) error {
+	p.resubmit(queryID, "", queue)
+	return nil
+}
+
+type kvResubmitter struct {
+	*resubmitter
+}
+
+func (p kvResubmitter) ProcessAndSubmit(_ context.Context, _ *relay.MessageKV, _ chan relay.PendingSubmittedTxInfo) error {
+	return fmt.Errorf("the update period must not be checked on rebroadcast")
+}
+
+func (p kvResubmitter) Rebroadcast(_ context.Context, m *relay.MessageKV, queue chan relay.PendingSubmittedTxInfo) error {
+	p.resubmit(m.QueryId, string(neutrontypes.InterchainQueryTypeKV), queue)
+	return nil
+}
+
+func txEvent(tx tmtypes.Tx, code uint32) coretypes.ResultEvent {
+	return coretypes.ResultEvent{
+		Data: tmtypes.EventDataTx{TxResult: abci.TxResult{
+			Height: 10,
+			Tx:     tx,
+			Result: abci.ResponseDeliverTx{Code: code},
+		}},
+	}
+}
+
+func TestProcessTxEvent(t *testing.T) {
+	tx := tmtypes.Tx("neutron tx")
+	// the hash is encoded the same way TxSender returns it
+	neutronHash := hex.EncodeToString(tx.Hash())
+
+	tests := []struct {
+		name         string
+		eventFirst   bool
+		code         uint32
+		expectStatus relay.SubmittedTxStatus
+	}{
+		{name: "tracked tx committed", code: abci.CodeTypeOK, expectStatus: relay.Committed},
+		{name: "tracked tx failed", code: 5, expectStatus: relay.ErrorOnCommit},
+		{name: "tx committed before tracked", eventFirst: true, code: abci.CodeTypeOK, expectStatus: relay.Committed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newStatusStorage()
+			tc := NewTxSubmitChecker(storage, nil, zap.NewNop(), "neutron1relayer", nil, nil, nil, 10, 0)
+			pending := relay.PendingSubmittedTxInfo{QueryID: 1, SubmittedTxHash: "remote", NeutronHash: neutronHash, SubmittedHeight: 5}
+
+			if tt.eventFirst {
+				tc.processTxEvent(txEvent(tx, tt.code))
+				tc.track(context.Background(), pending)
+			} else {
+				tc.track(context.Background(), pending)
+				tc.processTxEvent(txEvent(tx, tt.code))
+			}
+
+			status, ok := storage.statuses[neutronHash]
+			if !ok {
+				t.Fatalf("status of tx %s is not set", neutronHash)
+			}
+			if status.Status != tt.expectStatus {
+				t.Fatalf("expected status %s, got %s", tt.expectStatus, status.Status)
+			}
+			if len(tc.pending) != 0 || len(tc.earlyResults) != 0 {
+				t.Fatalf("tx is still tracked: %d pending, %d early results", len(tc.pending), len(tc.earlyResults))
+			}
+		})
+	}
+}
+
+func TestCheckPendingTx(t *testing.T) {
+	evictedTx := tmtypes.Tx("evicted tx")
+	neutronHash := hex.EncodeToString(evictedTx.Hash())
+	const (
+		submittedHeight = 100
+		deadlineBlocks  = 10
+	)
+
+	tests := []struct {
+		name      string
+		queryType string
+		client    *chainClient
+		checks    int
+		// expectRebroadcast is true if the tx is replaced by a rebroadcast one
+		expectRebroadcast bool
+		expectStatus      relay.SubmittedTxStatus
+	}{
+		{
+			name:   "in mempool",
+			client: &chainClient{height: submittedHeight + 5, mempool: []tmtypes.Tx{evictedTx}},
+			checks: 3,
+		},
+		{
+			name:   "single miss",
+			client: &chainClient{height: submittedHeight + 5},
+			checks: 1,
+		},
+		{
+			name:              "evicted tx query",
+			client:            &chainClient{height: submittedHeight + 5},
+			checks:            2,
+			expectRebroadcast: true,
+		},
+		{
+			name:              "evicted kv query",
+			queryType:         string(neutrontypes.InterchainQueryTypeKV),
+			client:            &chainClient{height: submittedHeight + 5},
+			checks:            2,
+			expectRebroadcast: true,
+		},
+		{
+			name:   "mempool too large to look through",
+			client: &chainClient{height: submittedHeight + 5, mempool: []tmtypes.Tx{tmtypes.Tx("other tx")}, mempoolTotal: 1000},
+			checks: 3,
+		},
+		{
+			name:         "deadline passed",
+			client:       &chainClient{height: submittedHeight + deadlineBlocks, mempool: []tmtypes.Tx{evictedTx}},
+			checks:       1,
+			expectStatus: relay.Expired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newStatusStorage()
+			resubmissions := &resubmitter{}
+			tc := NewTxSubmitChecker(storage, tt.client, zap.NewNop(), "neutron1relayer", nil,
+				txResubmitter{resubmissions}, kvResubmitter{resubmissions}, deadlineBlocks, 0)
+			tx := relay.PendingSubmittedTxInfo{
+				QueryID:         1,
+				SubmittedTxHash: "remote",
+				NeutronHash:     neutronHash,
+				QueryType:       tt.queryType,
+				SubmittedHeight: submittedHeight,
+			}
+			tc.track(context.Background(), tx)
+			storage.pending[neutronHash] = tx
+
+			for i := 0; i < tt.checks; i++ {
+				if pending, ok := tc.pending[neutronHash]; ok {
+					tc.checkPendingTx(context.Background(), pending)
+				}
+			}
+
+			if tt.expectStatus != "" {
+				if status := storage.statuses[neutronHash]; status.Status != tt.expectStatus {
+					t.Fatalf("expected status %s, got %q", tt.expectStatus, status.Status)
+				}
+				if len(tc.pending) != 0 {
+					t.Fatalf("tx is still tracked")
+				}
+				return
+			}
+			if status, ok := storage.statuses[neutronHash]; ok {
+				t.Fatalf("unexpected status %s", status.Status)
+			}
+
+			if !tt.expectRebroadcast {
+				if resubmissions.resubmissions != 0 {
+					t.Fatalf("tx is rebroadcast")
+				}
+				if _, ok := tc.pending[neutronHash]; !ok {
+					t.Fatalf("tx is not tracked anymore")
+				}
+				return
+			}
+
+			if resubmissions.resubmissions != 1 {
+				t.Fatalf("expected 1 rebroadcast, got %d", resubmissions.resubmissions)
+			}
+			if _, ok := tc.pending[neutronHash]; ok {
+				t.Fatalf("evicted tx is still tracked")
+			}
+			if _, ok := storage.removed[neutronHash]; !ok {
+				t.Fatalf("evicted tx is not removed from the pending queue")
+			}
+			if len(tc.pending) != 1 {
+				t.Fatalf("expected the rebroadcast tx to be tracked, got %d pending txs", len(tc.pending))
+			}
+			for rebroadcastHash, rebroadcastTx := range tc.pending {
+				if rebroadcastTx.SubmittedHeight != submittedHeight {
+					t.Fatalf("expected the rebroadcast tx submitted at %d, got %d", submittedHeight, rebroadcastTx.SubmittedHeight)
+				}
+				if stored := storage.pending[rebroadcastHash]; stored.SubmittedHeight != submittedHeight {
+					t.Fatalf("expected the stored rebroadcast tx submitted at %d, got %d", submittedHeight, stored.SubmittedHeight)
+				}
+
+				// the deadline of the rebroadcast tx counts from the original submission
+				tt.client.height = submittedHeight + deadlineBlocks
+				tc.checkPendingTx(context.Background(), rebroadcastTx)
+				if status := storage.statuses[rebroadcastHash]; status.Status != relay.Expired {
+					t.Fatalf("expected the rebroadcast tx to expire, got %q", status.Status)
+				}
+			}
+		})
+	}
+}